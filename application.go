@@ -17,6 +17,14 @@ var RefreshRate = 40
 
 var ErrUnitialized = errors.New("tview is unitialized")
 
+// ErrNotRunning is returned by Suspend() if the application is not running or
+// already suspended.
+var ErrNotRunning = errors.New("application is not running or already suspended")
+
+// QuitAction is the name of the action in an application's default keymap
+// which calls Application.Quit(). It is bound to Ctrl-C.
+const QuitAction = "quit"
+
 // Application represents the top node of an application.
 //
 // It is not strictly required to use this class as none of the other classes
@@ -39,23 +47,97 @@ type Application struct {
 
 	MouseSupport bool
 
+	// Whether or not clicking a primitive moves the keyboard focus to it.
+	clickToFocus bool
+
+	// Whether or not the keyboard focus follows the mouse pointer.
+	focusFollowsMouse bool
+
+	// The state of the mouse between mouse events.
+	mouse mouseState
+
+	// Whether or not the terminal was asked to bracket pasted text and to
+	// report focus changes. If so, key events are run through the paste
+	// decoder.
+	bracketedPaste bool
+	paste          pasteDecoder
+
+	// Whether or not the screen sends escape sequences to a terminal, e.g. to
+	// set the window title.
+	escapeCapable bool
+
+	// An optional callback function which is invoked when the terminal window
+	// receives or loses focus, and whether it lost focus.
+	terminalFocus   func(focused bool)
+	terminalBlurred bool
+
+	// The terminal window title set with SetTitle() and whether the terminal's
+	// previous title was saved on its title stack.
+	title       string
+	titlePushed bool
+
+	// The text stored with SetClipboard().
+	clipboard string
+
 	// The primitive which currently has the keyboard focus.
 	focus Primitive
 
+	// The focus traps installed with PushFocusTrap(), the one in effect last.
+	focusTraps []focusTrap
+
+	// The number of nested SetFocus() calls in progress and the primitive which
+	// had focus before the outermost one.
+	focusing    int
+	focusOrigin Primitive
+
+	// An optional callback function which is invoked when the focus moved to
+	// a different primitive.
+	focusChanged func(previous, current Primitive)
+
 	// The root primitive to be seen on the screen.
 	root Primitive
 
+	// Whether or not the application is suspended (see Suspend()).
+	suspended bool
+
+	// The number of times the application was suspended. The screen event
+	// loop uses it to detect suspensions whose screen finalization it missed.
+	suspensions int
+
 	// Whether or not the application resizes the root primitive.
 	rootFullscreen bool
 
-	// Ticker for the refresh rate
-	drawTicker *time.Ticker
+	// Receives a value when a redraw is requested. Multiple requests made
+	// before the next redraw are coalesced into one.
+	drawRequests chan struct{}
+
+	// Whether or not the screen is redrawn immediately after each event instead
+	// of at the refresh rate.
+	synchronousDraw bool
+
+	// Channels which are closed when the next frame was flushed to the screen.
+	frameWaiters []chan struct{}
+
+	// Functions scheduled to run on the event loop (see AfterFunc()).
+	timers []*Timer
 
 	// An optional capture function which receives a key event and returns the
 	// event to be forwarded to the default input handler (nil if nothing should
 	// be forwarded).
 	inputCapture func(event *tcell.EventKey) *tcell.EventKey
 
+	// An optional keymap which receives key events after the input capture
+	// function.
+	keymap *Keymap
+
+	// An optional callback function which is invoked by Quit() and which may
+	// prevent the application from stopping.
+	beforeQuit func() bool
+
+	// An optional callback function which is invoked when the application has
+	// stopped, just before Run() returns.
+	shutdown func()
+
 	// An optional callback function which is invoked just before the root
 	// primitive is drawn.
 	beforeDraw func(screen tcell.Screen) bool
@@ -70,9 +152,6 @@ type Application struct {
 	// Functions queued from goroutines, used to serialize updates to primitives.
 	updates chan func()
 
-	// only draw if true
-	draw bool
-
 	// An object that the screen variable will be set to after Fini() was called.
 	// Use this channel to set a new screen object for the application
 	// (screen.Init() and draw() will be called implicitly). A value of nil will
@@ -80,12 +159,29 @@ type Application struct {
 	screenReplacement chan tcell.Screen
 }
 
+// NewApplication creates and returns a new application. Its keymap binds
+// Ctrl-C to the QuitAction, Tab to the FocusNextAction, and Backtab to the
+// FocusPreviousAction. It also contains the other focus navigation actions,
+// the clipboard actions, and the SuspendAction, such as FocusLeftAction and
+// CopyAction, without binding them (see GetKeymap()).
 func NewApplication() *Application {
-	return &Application{
+	a := &Application{
 		events:            make(chan tcell.Event, QueueSize),
+		updates:           make(chan func(), QueueSize),
+		drawRequests:      make(chan struct{}, 1),
 		screenReplacement: make(chan tcell.Screen, 1),
 		MouseSupport:      true,
 	}
+
+	a.keymap = NewKeymap().SetAction(QuitAction, "Quit the application", nil, a.Quit)
+	a.keymap.Bind(QuitAction, "Ctrl-C")
+	a.addFocusActions(a.keymap)
+	a.keymap.Bind(FocusNextAction, "Tab")
+	a.keymap.Bind(FocusPreviousAction, "Backtab")
+	a.addClipboardActions(a.keymap)
+	a.addJobControlActions(a.keymap)
+
+	return a
 }
 
 func (a *Application) Run() (err error) {
@@ -106,17 +202,16 @@ func (a *Application) Run() (err error) {
 			a.Unlock()
 			return err
 		}
-
-		if a.MouseSupport {
-			// Enable mouse
-			a.Screen.EnableMouse()
-		}
 	}
 
+	a.prepareScreen(a.Screen)
+	terminal := controlsTerminal(a.Screen)
+
 	// We catch panics to clean up because they mess up the terminal.
 	defer func() {
 		if p := recover(); p != nil {
 			if a.Screen != nil {
+				a.disableTerminalModes(a.Screen)
 				a.Screen.Fini()
 			}
 			panic(p)
@@ -127,128 +222,167 @@ func (a *Application) Run() (err error) {
 	a.Unlock()
 	a.forceDraw()
 
+	// Support job control when running on a terminal.
+	if terminal {
+		defer a.watchJobControl()()
+	}
+
 	// Separate loop to wait for screen events.
 	var wg sync.WaitGroup
 
+	wg.Add(1)
 	go func() {
-		wg.Add(1)
 		defer wg.Done()
 
+		a.RLock()
+		suspensions := a.suspensions
+		a.RUnlock()
+
 		for {
 			a.RLock()
 			screen := a.Screen
+			suspended := a.suspensions != suspensions
 			a.RUnlock()
 			if screen == nil {
 				// We have no screen. Let's stop.
-				QueueEvent(nil)
+				a.QueueEvent(nil)
 				break
 			}
 
-			// Wait for next event and queue it.
-			event := screen.PollEvent()
-			if event != nil {
-				// Regular event. Queue.
-				QueueEvent(event)
-				continue
+			// Wait for next event and queue it. If the screen was suspended
+			// and initialized again before we noticed, don't poll it.
+			if !suspended {
+				event := screen.PollEvent()
+				if event != nil {
+					// Regular event. Queue.
+					a.QueueEvent(event)
+					continue
+				}
 			}
 
 			// A screen was finalized (event is nil). Wait for a new scren.
 			screen = <-a.screenReplacement
 			if screen == nil {
 				// No new screen. We're done.
-				QueueEvent(nil)
+				a.QueueEvent(nil)
 				return
 			}
 
 			// We have a new screen. Keep going.
 			a.Lock()
-			a.Screen = screen
-			a.Unlock()
-
-			// Initialize and draw this screen.
-			if err := screen.Init(); err != nil {
-				panic(err)
+			suspensions = a.suspensions
+			if a.Screen == nil {
+				// Stop() was called after the screen was replaced. Discard
+				// its request to stop, too.
+				select {
+				case <-a.screenReplacement:
+				default:
+				}
+				a.Unlock()
+				a.QueueEvent(nil)
+				return
 			}
+			if a.Screen != screen {
+				// Initialize this screen. (Suspend() did this already.)
+				a.Screen = screen
+				if err := screen.Init(); err != nil {
+					a.Unlock()
+					panic(err)
+				}
+				a.prepareScreen(screen)
+			}
+			a.Unlock()
 
 			a.Draw()
 		}
 	}()
 
-	stopDraw := make(chan struct{})
-	go func() {
-		wg.Add(1)
-		defer wg.Done()
-
-		drawTicker := time.NewTicker(time.Second / time.Duration(RefreshRate))
-		for {
-			select {
-			case <-drawTicker.C:
-				if a.draw {
-					a.forceDraw()
-					a.draw = false
-				}
-			case <-stopDraw:
-				drawTicker.Stop()
-				return
-			}
-		}
-	}()
+	// Redraw requests are collected and drawn on the next tick of the refresh
+	// rate or, in synchronous draw mode, after each event. Timers are run on
+	// the same ticks.
+	drawTicker := time.NewTicker(time.Second / time.Duration(RefreshRate))
+	defer drawTicker.Stop()
+	var drawPending bool
 
 	// Start event loop.
 EventLoop:
 	for {
+		// Closed once the current event was processed (see QueueEventWait()).
+		var processed chan struct{}
+
 		select {
+		case now := <-drawTicker.C:
+			if a.runTimers(now) {
+				drawPending = true
+			}
+			if drawPending {
+				drawPending = false
+				a.forceDraw()
+			}
+			continue
+
+		case <-a.drawRequests:
+			drawPending = true
+
+		case update := <-a.updates:
+			update()
+
 		case event := <-a.events:
+			if queued, ok := event.(*queuedEvent); ok {
+				event, processed = queued.Event, queued.processed
+			}
 			if event == nil {
+				if processed != nil {
+					close(processed)
+				}
 				break EventLoop
 			}
 
 			switch event := event.(type) {
 			case *tcell.EventKey:
 				a.RLock()
-				p := a.focus
-				inputCapture := a.inputCapture
+				bracketedPaste := a.bracketedPaste
 				a.RUnlock()
 
-				// Intercept keys.
-				if inputCapture != nil {
-					if event = inputCapture(event); event == nil {
-						a.Draw()
-						continue // Don't forward event.
-					}
+				if !bracketedPaste {
+					a.handleKey(event)
+					break
 				}
 
-				// Ctrl-C closes the a.
-				if event.Key() == tcell.KeyCtrlC {
-					a.Stop()
+				keys, decoded, started := a.paste.decode(event)
+				if started {
+					generation := a.paste.generation
+					time.AfterFunc(PasteTimeout, func() {
+						a.QueueEvent(&pasteTimeoutEvent{t: time.Now(), generation: generation})
+					})
+				}
+				for _, key := range keys {
+					a.handleKey(key)
+				}
+				switch decoded := decoded.(type) {
+				case *EventPaste:
+					a.handlePaste(decoded)
+				case *EventTerminalFocus:
+					a.handleTerminalFocus(decoded)
 				}
 
-				// Pass other key events to the currently focused primitive.
-				if p != nil {
-					if handler := p.InputHandler(); handler != nil {
-						handler(event, func(p Primitive) {
-							SetFocus(p)
-						})
-
-						a.Draw()
-					}
+			case *pasteTimeoutEvent:
+				for _, key := range a.paste.flush(event.generation) {
+					a.handleKey(key)
 				}
 
-			case *tcell.EventMouse:
-				a.RLock()
-				atXY := GetComponentAt(event.Position())
-				a.RUnlock()
+			case *EventPaste:
+				a.handlePaste(event)
 
-				if atXY == nil {
-					continue
-				}
+			case *EventTerminalFocus:
+				a.handleTerminalFocus(event)
 
-				mouseSupport, ok := (*atXY).(MouseSupport)
-				if !ok {
-					continue
-				}
+			case *streamEndEvent:
+				err = event.err
+				a.Stop()
 
-				if handler := mouseSupport.MouseHandler(); handler != nil && handler(event) {
+			case *tcell.EventMouse:
+				if a.handleMouse(event) {
 					a.Draw()
 				}
 
@@ -258,25 +392,174 @@ EventLoop:
 				a.RUnlock()
 
 				if screen == nil {
-					continue
+					break
 				}
 
 				screen.Clear()
 				a.Draw()
 			}
 		}
-	}
 
-	stopDraw <- struct{}{}
+		// In synchronous draw mode, draw right away if the event requested it.
+		a.RLock()
+		synchronousDraw := a.synchronousDraw
+		a.RUnlock()
+		if synchronousDraw {
+			select {
+			case <-a.drawRequests:
+				drawPending = true
+			default:
+			}
+			if drawPending {
+				drawPending = false
+				a.forceDraw()
+			}
+		}
+
+		if processed != nil {
+			close(processed)
+		}
+	}
 
 	// Wait for the event loop to finish.
 	wg.Wait()
+
+	// Nobody is going to draw these frames anymore.
+	a.Lock()
 	a.Screen = nil
+	for _, frame := range a.frameWaiters {
+		close(frame)
+	}
+	a.frameWaiters = nil
+	shutdown := a.shutdown
+	a.Unlock()
+
+	if shutdown != nil {
+		shutdown()
+	}
+
+	return err
+}
+
+// handleKey forwards a key event to the input capture function, then to the
+// keymap, and then to the primitive which has focus.
+func (a *Application) handleKey(event *tcell.EventKey) {
+	a.RLock()
+	p := a.focus
+	inputCapture := a.inputCapture
+	keymap := a.keymap
+	a.RUnlock()
+
+	// Intercept keys.
+	if inputCapture != nil {
+		if event = inputCapture(event); event == nil {
+			a.Draw()
+			return // Don't forward event.
+		}
+	}
+
+	// Trigger key bindings.
+	if keymap != nil && keymap.handle(event, a.focusedPath()) {
+		a.Draw()
+		return
+	}
+
+	// Pass other key events to the currently focused primitive.
+	if p != nil {
+		if handler := p.InputHandler(); handler != nil {
+			handler(event, func(p Primitive) {
+				a.SetFocus(p)
+			})
+
+			a.Draw()
+		}
+	}
+}
+
+// handlePaste hands pasted text to the primitive which has focus. If that
+// primitive does not implement PasteSupport, the text is replayed as key
+// events.
+func (a *Application) handlePaste(event *EventPaste) {
+	a.RLock()
+	p := a.focus
+	a.RUnlock()
+
+	if pasteSupport, ok := p.(PasteSupport); ok {
+		if handler := pasteSupport.PasteHandler(); handler != nil {
+			handler(event.Text())
+			a.Draw()
+			return
+		}
+	}
 
-	return nil
+	for _, key := range event.keys() {
+		a.handleKey(key)
+	}
+}
+
+// Quit asks the application to stop. If a function was installed with
+// SetBeforeQuitFunc(), it is called first and the application only stops if it
+// returns true. Otherwise, Quit() is the same as Stop(). This is what Ctrl-C
+// does by default (see QuitAction).
+func (a *Application) Quit() {
+	a.RLock()
+	beforeQuit := a.beforeQuit
+	a.RUnlock()
+
+	if beforeQuit != nil && !beforeQuit() {
+		a.Draw()
+		return
+	}
+
+	a.Stop()
 }
 
-// Stop stops the application, causing Run() to return.
+// SetBeforeQuitFunc installs a callback function which is invoked when Quit()
+// is called, e.g. because the user pressed Ctrl-C. If it returns false, the
+// application keeps running. To ask the user for confirmation, the function
+// may show a Modal and return false, calling Stop() later if the user confirms.
+//
+// Provide nil to uninstall the callback function.
+func (a *Application) SetBeforeQuitFunc(handler func() bool) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.beforeQuit = handler
+}
+
+// GetBeforeQuitFunc returns the callback function installed with
+// SetBeforeQuitFunc() or nil if none has been installed.
+func (a *Application) GetBeforeQuitFunc() func() bool {
+	a.RLock()
+	defer a.RUnlock()
+
+	return a.beforeQuit
+}
+
+// SetShutdownFunc installs a callback function which is invoked when the
+// application has stopped, regardless of what stopped it. It is called after
+// the terminal was restored, just before Run() returns, so it may e.g. print
+// to the standard output or save state.
+//
+// Provide nil to uninstall the callback function.
+func (a *Application) SetShutdownFunc(handler func()) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.shutdown = handler
+}
+
+// GetShutdownFunc returns the callback function installed with
+// SetShutdownFunc() or nil if none has been installed.
+func (a *Application) GetShutdownFunc() func() {
+	a.RLock()
+	defer a.RUnlock()
+
+	return a.shutdown
+}
+
+// Stop stops the application, causing Run() to return. Unlike Quit(), this
+// does not consult the function installed with SetBeforeQuitFunc().
 func (a *Application) Stop() {
 	a.Lock()
 	defer a.Unlock()
@@ -285,6 +568,7 @@ func (a *Application) Stop() {
 		return
 	}
 
+	a.disableTerminalModes(a.Screen)
 	a.Screen.Fini()
 	a.Screen = nil
 	a.screenReplacement <- nil
@@ -292,9 +576,53 @@ func (a *Application) Stop() {
 
 // Draw refreshes the screen (during the next update cycle). It calls the Draw()
 // function of the application's root primitive and then syncs the screen
-// buffer.
+// buffer. Multiple calls before the next update cycle result in a single
+// redraw. This function may be called from any goroutine.
 func (a *Application) Draw() {
-	a.draw = true
+	select {
+	case a.drawRequests <- struct{}{}:
+	default:
+		// A redraw is already pending.
+	}
+}
+
+// SetSynchronousDraw sets whether or not the screen is redrawn immediately
+// after each event which requested a redraw (e.g. a key press handled by the
+// focused primitive), instead of at the next tick of RefreshRate. This makes
+// drawing deterministic, which is useful for tests and applications which need
+// low latency. It is disabled by default.
+func (a *Application) SetSynchronousDraw(enable bool) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.synchronousDraw = enable
+}
+
+// GetSynchronousDraw returns whether or not the screen is redrawn immediately
+// after each event. See SetSynchronousDraw() for details.
+func (a *Application) GetSynchronousDraw() bool {
+	a.RLock()
+	defer a.RUnlock()
+
+	return a.synchronousDraw
+}
+
+// WaitForFrame requests a redraw and blocks until it was flushed to the screen.
+// It returns immediately if the application is not running. It must not be
+// called from the goroutine which runs the application's event loop (e.g. from
+// event handlers), as that goroutine performs the redraw.
+func (a *Application) WaitForFrame() {
+	a.Lock()
+	if a.Screen == nil {
+		a.Unlock()
+		return
+	}
+	frame := make(chan struct{})
+	a.frameWaiters = append(a.frameWaiters, frame)
+	a.Unlock()
+
+	a.Draw()
+	<-frame
 }
 
 // ForceDraw refreshes the screen immediately. Use this function with caution as
@@ -309,60 +637,141 @@ func (a *Application) ForceDraw() {
 }
 
 // Suspend temporarily suspends the application by exiting terminal UI mode and
-// invoking the provided function "f". When "f" returns nil, terminal UI mode is
-// entered again and the application resumes. If "f" returns an error, the
-// application safely panics.
+// invoking the provided function "f". When "f" returns, terminal UI mode is
+// entered again and the application resumes with the same configuration
+// (mouse support, bracketed paste, terminal focus reporting, and the terminal
+// title), redrawing the whole screen. The error returned by "f" is returned.
 //
-// A return value of true indicates that the application was suspended and "f"
-// was called. If false is returned, the application was already suspended,
-// terminal UI mode was not exited, and "f" was not called.
-func (a *Application) Suspend(f func() error) bool {
-	a.RLock()
-	screen := application.Screen
-	a.RUnlock()
-
-	if screen == nil {
-		return false // Screen has not yet been initialized.
+// Screens provided with SetScreen() are initialized again. Terminal screens
+// created by tcell cannot be initialized a second time, so they are replaced
+// with a new terminal screen. Key presses made while "f" runs go to "f". On
+// terminal screens, those which "f" doesn't read are discarded when terminal
+// UI mode is entered again, as are key presses which the screen had not yet
+// handed to the application when Suspend() was called.
+//
+// If the application is not running or is already suspended, "f" is not
+// called and ErrNotRunning is returned. If terminal UI mode cannot be entered
+// again, the application is stopped and the error is returned.
+func (a *Application) Suspend(f func() error) error {
+	a.Lock()
+	screen := a.Screen
+	if screen == nil || a.suspended {
+		a.Unlock()
+		return ErrNotRunning
 	}
+	a.suspended = true
+	a.suspensions++
+	a.disableTerminalModes(screen)
+	a.Unlock()
 
 	// Enter suspended mode.
 	screen.Fini()
 
 	// Wait for "f" to return.
-	if err := f(); err != nil {
-		panic(err)
-	}
+	err := f()
 
-	// Make a new screen.
-	s, err := tcell.NewScreen()
-	if err != nil {
-		panic(err)
+	// Prepare the screen for terminal UI mode.
+	var initErr error
+	if isTerminalScreen(screen) {
+		screen, initErr = tcell.NewScreen()
 	}
-
 	a.Lock()
-	a.Screen = s
-	a.Unlock()
+	defer a.Unlock()
+	a.suspended = false
+	if a.Screen == nil {
+		return err // Stop() was called in the meantime.
+	}
+	if initErr == nil {
+		initErr = screen.Init()
+	}
+	if initErr != nil {
+		a.Screen = nil
+		a.screenReplacement <- nil
+		return initErr
+	}
+	a.Screen = screen
+	a.prepareScreen(screen)
 
-	// One key event will get lost, see https://github.com/rivo/tcell/issues/194
+	// Continue application loop.
 	a.screenReplacement <- screen
 
-	// Continue application loop.
-	return true
+	return err
 }
 
 // ExecApplication takes in a function and pass in the application. This is intended
-// for widgets/primitives to use to trigger a draw by itself.
+// for widgets/primitives to use to trigger a draw by itself. The function is
+// called on the caller's goroutine. Use QueueUpdate() to access primitives from
+// other goroutines.
 func (a *Application) ExecApplication(f func(*Application) bool) {
 	if f(a) {
-		Draw()
+		a.Draw()
 	}
 }
 
+// QueueUpdate is used to synchronize access to primitives from non-main
+// goroutines. The provided function will be executed as part of the event
+// loop, between events, and thus will not cause race conditions with other
+// such update functions or the Draw() function.
+//
+// Note that Draw() is not implicitly called after the execution of f as that
+// may not be desirable. You can call Draw() from f if the screen should be
+// refreshed after each update. Alternatively, use QueueUpdateDraw() to follow
+// up with a refresh of the screen.
+//
+// This function returns as soon as f was queued. It blocks if the queue is
+// full, so it should not be called from the event loop (e.g. from key event
+// callbacks), where the primitives may be accessed directly anyway.
+func (a *Application) QueueUpdate(f func()) {
+	a.updates <- f
+}
+
+// QueueUpdateDraw works like QueueUpdate() except it refreshes the screen
+// after executing f.
+func (a *Application) QueueUpdateDraw(f func()) {
+	a.QueueUpdate(func() {
+		f()
+		a.Draw()
+	})
+}
+
+// QueueUpdateWait works like QueueUpdate() except it only returns after f was
+// executed. If the application is not running yet, it waits until it is. Never
+// call this function from the event loop (e.g. from key event callbacks) as
+// it will wait forever.
+func (a *Application) QueueUpdateWait(f func()) {
+	done := make(chan struct{})
+	a.QueueUpdate(func() {
+		defer close(done)
+		f()
+	})
+	<-done
+}
+
 // QueueEvent sends an event to the Application event loop.
 //
 // It is not recommended for event to be nil.
 func (a *Application) QueueEvent(event tcell.Event) {
-	application.events <- event
+	a.events <- event
+}
+
+// QueueEventWait works like QueueEvent() except it only returns after the event
+// was processed by the event loop and, in synchronous draw mode (see
+// SetSynchronousDraw()), the screen was redrawn. Events queued with this
+// function are processed in order with all other events. Never call this
+// function from the event loop (e.g. from key event callbacks) as it will wait
+// forever.
+func (a *Application) QueueEventWait(event tcell.Event) {
+	processed := make(chan struct{})
+	a.QueueEvent(&queuedEvent{Event: event, processed: processed})
+	<-processed
+}
+
+// queuedEvent is an event sent with QueueEventWait().
+type queuedEvent struct {
+	tcell.Event
+
+	// Closed once the event was processed.
+	processed chan struct{}
 }
 
 // SetInputCapture sets a function which captures all key events before they are
@@ -372,18 +781,102 @@ func (a *Application) QueueEvent(event tcell.Event) {
 // nil.
 //
 // Note that this also affects the default event handling of the application
-// itself: Such a handler can intercept the Ctrl-C event which closes the
-// applicatoon.
+// itself: Such a handler sees key events before the application's keymap (see
+// SetKeymap()) and can thus intercept e.g. the Ctrl-C event which quits the
+// application.
 func (a *Application) SetInputCapture(capture func(event *tcell.EventKey) *tcell.EventKey) {
+	a.Lock()
+	defer a.Unlock()
+
 	a.inputCapture = capture
 }
 
 // GetInputCapture returns the function installed with SetInputCapture() or nil
 // if no such function has been installed.
 func (a *Application) GetInputCapture() func(event *tcell.EventKey) *tcell.EventKey {
+	a.RLock()
+	defer a.RUnlock()
+
 	return a.inputCapture
 }
 
+// SetKeymap installs a keymap which maps keys to actions (see Keymap). It
+// receives key events after the input capture function (see SetInputCapture())
+// and before the primitive which has focus. Provide nil to remove the keymap.
+//
+// This replaces the application's default keymap, which binds Ctrl-C to the
+// QuitAction. To keep that binding, add actions to the default keymap returned
+// by GetKeymap() instead. To change or remove the quit key, rebind or remove
+// the QuitAction.
+func (a *Application) SetKeymap(keymap *Keymap) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.keymap = keymap
+}
+
+// GetKeymap returns the application's keymap or nil if the keymap was removed
+// with SetKeymap().
+func (a *Application) GetKeymap() *Keymap {
+	a.RLock()
+	defer a.RUnlock()
+
+	return a.keymap
+}
+
+// ActiveBindings returns the key bindings of the application's keymap which can
+// currently be triggered, given the primitive which has focus. This can be used
+// to generate help screens. Bindings shadowed by bindings of an inner scope are
+// omitted.
+func (a *Application) ActiveBindings() []Binding {
+	keymap := a.GetKeymap()
+	if keymap == nil {
+		return nil
+	}
+
+	return keymap.ActiveBindings(a.focusedPath())
+}
+
+// SetClickToFocus sets whether or not clicking a primitive with the mouse moves
+// the keyboard focus to it, as if SetFocus() was called on it. Primitives whose
+// InputHandler() returns nil never receive the focus this way. This is
+// disabled by default.
+func (a *Application) SetClickToFocus(enable bool) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.clickToFocus = enable
+}
+
+// GetClickToFocus returns whether or not clicking a primitive moves the
+// keyboard focus to it. See SetClickToFocus() for details.
+func (a *Application) GetClickToFocus() bool {
+	a.RLock()
+	defer a.RUnlock()
+
+	return a.clickToFocus
+}
+
+// SetFocusFollowsMouse sets whether or not the keyboard focus follows the mouse
+// pointer, i.e. whether the primitive under the pointer receives the focus
+// whenever the mouse is moved. Primitives whose InputHandler() returns nil
+// never receive the focus this way. This is disabled by default.
+func (a *Application) SetFocusFollowsMouse(enable bool) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.focusFollowsMouse = enable
+}
+
+// GetFocusFollowsMouse returns whether or not the keyboard focus follows the
+// mouse pointer. See SetFocusFollowsMouse() for details.
+func (a *Application) GetFocusFollowsMouse() bool {
+	a.RLock()
+	defer a.RUnlock()
+
+	return a.focusFollowsMouse
+}
+
 // SetScreen allows you to provide your own tcell.Screen object. For most
 // applications, this is not needed and you should be familiar with
 // tcell.Screen when using this function.
@@ -395,19 +888,54 @@ func (a *Application) SetScreen(screen tcell.Screen) {
 		return // Invalid input. Do nothing.
 	}
 
-	application.Lock()
-	if application.Screen == nil {
+	a.Lock()
+	if a.Screen == nil {
 		// Run() has not been called yet.
-		application.Screen = screen
-		application.Unlock()
+		a.Screen = screen
+		a.Unlock()
 		return
 	}
 
 	// Run() is already in progress. Exchange screen.
-	oldScreen := application.Screen
-	application.Unlock()
+	oldScreen := a.Screen
+	a.disableTerminalModes(oldScreen)
+	a.Unlock()
 	oldScreen.Fini()
-	application.screenReplacement <- screen
+	a.screenReplacement <- screen
+}
+
+// prepareScreen applies the application's configuration to a screen which was
+// just initialized. The application must be locked.
+func (a *Application) prepareScreen(screen tcell.Screen) {
+	if a.MouseSupport {
+		screen.EnableMouse()
+	}
+	a.enableTerminalModes(screen)
+}
+
+// isTerminalScreen returns true if the given screen is one of tcell's terminal
+// screens, as returned by tcell.NewScreen(). These cannot be initialized again
+// after Fini() was called. Apart from StreamScreen, they are the only screens
+// which send escape sequences to the terminal.
+func isTerminalScreen(screen tcell.Screen) bool {
+	if _, ok := screen.(*StreamScreen); ok {
+		return false
+	}
+	_, ok := screen.(interface{ TPuts(string) })
+	return ok
+}
+
+// fullscreenSize returns the size of the area a root primitive which fills the
+// screen occupies. On inline screens, this is the minimum number of lines the
+// screen occupies (see NewInlineScreen()).
+func fullscreenSize(screen tcell.Screen) (width, height int) {
+	width, height = screen.Size()
+	if inline, ok := screen.(*StreamScreen); ok {
+		if lines := inline.GetInlineLines(); lines > 0 && lines < height {
+			height = lines
+		}
+	}
+	return
 }
 
 // forceDraw actually does what Draw() promises to do.
@@ -415,6 +943,14 @@ func (a *Application) forceDraw() *Application {
 	a.Lock()
 	defer a.Unlock()
 
+	// Release everyone waiting for this frame, even if nothing is drawn.
+	defer func() {
+		for _, frame := range a.frameWaiters {
+			close(frame)
+		}
+		a.frameWaiters = nil
+	}()
+
 	screen := a.Screen
 	root := a.root
 	fullscreen := a.rootFullscreen
@@ -422,13 +958,13 @@ func (a *Application) forceDraw() *Application {
 	after := a.afterDraw
 
 	// Maybe we're not ready yet or not anymore.
-	if screen == nil || root == nil {
+	if screen == nil || root == nil || a.suspended {
 		return a
 	}
 
 	// Resize if requested.
 	if fullscreen && root != nil {
-		width, height := screen.Size()
+		width, height := fullscreenSize(screen)
 		root.SetRect(0, 0, width, height)
 	}
 
@@ -510,7 +1046,7 @@ func (a *Application) SetRoot(root Primitive, fullscreen bool) {
 
 	a.Unlock()
 
-	SetFocus(root)
+	a.SetFocus(root)
 }
 
 // GetRoot returns the current root of the application.
@@ -526,7 +1062,7 @@ func (a *Application) GetRoot() Primitive {
 // screen.
 func (a *Application) ResizeToFullScreen(p Primitive) {
 	a.RLock()
-	width, height := a.Screen.Size()
+	width, height := fullscreenSize(a.Screen)
 	a.RUnlock()
 
 	p.SetRect(0, 0, width, height)
@@ -537,9 +1073,18 @@ func (a *Application) ResizeToFullScreen(p Primitive) {
 // events.
 //
 // Blur() will be called on the previously focused primitive. Focus() will be
-// called on the new primitive.
+// called on the new primitive. Once the focus has settled (containers may hand
+// it on to one of their children), the callbacks installed with
+// Box.SetBlurFunc() and Box.SetFocusFunc() are invoked for the primitives which
+// lost or received the focus, including their containers, followed by the
+// callback installed with SetFocusChangedFunc().
 func (a *Application) SetFocus(p Primitive) {
 	a.Lock()
+	if a.focusing == 0 {
+		a.focusOrigin = a.focus
+	}
+	a.focusing++
+
 	if a.focus != nil {
 		a.focus.Blur()
 	}
@@ -556,6 +1101,48 @@ func (a *Application) SetFocus(p Primitive) {
 			a.SetFocus(p)
 		})
 	}
+
+	// Notify about the change once the outermost call is done.
+	a.Lock()
+	a.focusing--
+	if a.focusing > 0 {
+		a.Unlock()
+		return
+	}
+	previous, current := a.focusOrigin, a.focus
+	a.focusOrigin = nil
+	root := a.root
+	focusChanged := a.focusChanged
+	a.Unlock()
+
+	if previous != current {
+		notifyFocus(root, previous, current)
+		if focusChanged != nil {
+			focusChanged(previous, current)
+		}
+	}
+}
+
+// SetFocusChangedFunc installs a callback function which is invoked whenever
+// the keyboard focus moves from one primitive to another (see SetFocus()). It
+// receives the primitive which had focus before and the one which has it now,
+// either of which may be nil.
+//
+// Provide nil to uninstall the callback function.
+func (a *Application) SetFocusChangedFunc(handler func(previous, current Primitive)) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.focusChanged = handler
+}
+
+// GetFocusChangedFunc returns the callback function installed with
+// SetFocusChangedFunc() or nil if none has been installed.
+func (a *Application) GetFocusChangedFunc() func(previous, current Primitive) {
+	a.RLock()
+	defer a.RUnlock()
+
+	return a.focusChanged
 }
 
 // GetFocus returns the primitive which has the current focus. If none has it,
@@ -570,48 +1157,68 @@ func (a *Application) GetFocus() Primitive {
 // GetComponentAt returns the highest level component at the given coordinates
 // or zero if no component can be found.
 func (a *Application) GetComponentAt(x, y int) *Primitive {
-	return getComponentAtRecursively(a.root, x, y)
+	path := getComponentPath(a.root, x, y)
+	if len(path) == 0 {
+		return nil
+	}
+
+	return &path[len(path)-1]
 }
 
-func getComponentAtRecursively(primitive Primitive, x, y int) *Primitive {
-	flex, isFlex := primitive.(*Flex)
-	if isFlex {
-		for _, child := range flex.items {
-			found := getComponentAtRecursively(child.Item, x, y)
-			if found != nil {
-				return found
-			}
+// getMousePath returns the primitives which receive a mouse event at the given
+// coordinates, starting at the root primitive and ending at the primitive under
+// the pointer. The focused primitive is drawn on top of its surroundings (e.g.
+// an open drop-down list), so it gets the first pick.
+func (a *Application) getMousePath(x, y int) []Primitive {
+	if hasMouseHandler(a.focus) && inRect(a.focus, x, y) {
+		if path := getPrimitivePath(a.root, a.focus); path != nil {
+			return path
 		}
+		return []Primitive{a.focus}
 	}
 
-	grid, isGrid := primitive.(*Grid)
-	if isGrid {
-		for _, child := range grid.items {
-			found := getComponentAtRecursively(child.Item, x, y)
-			if found != nil {
-				return found
-			}
-		}
-	}
+	return getComponentPath(a.root, x, y)
+}
 
-	pages, isPages := primitive.(*Pages)
-	if isPages {
-		for _, page := range pages.pages {
-			if page.Visible {
-				found := getComponentAtRecursively(page.Item, x, y)
-				if found != nil {
-					return found
-				}
-				break
-			}
+// focusedPath returns the primitives from the root primitive down to the
+// primitive which has focus. If the focused primitive is not part of the root's
+// tree, only the focused primitive is returned.
+func (a *Application) focusedPath() []Primitive {
+	a.RLock()
+	root, focus := a.root, a.focus
+	a.RUnlock()
+
+	return getFocusPath(root, focus)
+}
+
+// focusPath moves the keyboard focus to the last primitive of the given path
+// which accepts key events. Containers on the path which hand their focus on to
+// their children are told where the focus is supposed to go. The focus does not
+// leave the focus trap in effect, if any. Returns true if the focus was
+// changed.
+func (a *Application) focusPath(path []Primitive) bool {
+	// Find the innermost primitive which accepts key events.
+	target := -1
+	for index := len(path) - 1; index >= 0; index-- {
+		if path[index].InputHandler() != nil {
+			target = index
+			break
 		}
 	}
+	if target < 0 || path[target].GetFocusable().HasFocus() || !a.inFocusTrap(path[target]) {
+		return false
+	}
 
-	componentX, componentY, width, height := primitive.GetRect()
-	// Subtracting -1 from height and width, since we got a pixel with coordinate already.
-	if componentX <= x && componentY <= y && (componentX+width-1) >= x && (componentY+height-1) >= y {
-		return &primitive
+	// If there are containers managing the focus of their children, the
+	// outermost one needs to receive the focus.
+	focus := path[target]
+	for index := target - 1; index >= 0; index-- {
+		if delegator, ok := path[index].(focusDelegator); ok {
+			delegator.focusChild(path[index+1])
+			focus = path[index]
+		}
 	}
 
-	return nil
+	a.SetFocus(focus)
+	return true
 }
@@ -51,11 +51,24 @@ type Box struct {
 	// Whether or not this box has focus.
 	hasFocus bool
 
+	// Optional callback functions invoked when the box or one of its
+	// descendants receives or loses focus.
+	focusFunc, blurFunc func()
+
 	// An optional capture function which receives a key event and returns the
 	// event to be forwarded to the primitive's default input handler (nil if
 	// nothing should be forwarded).
 	inputCapture func(event *tcell.EventKey) *tcell.EventKey
 
+	// An optional capture function which receives a mouse event and returns the
+	// event to be forwarded to the primitives below the pointer (nil if nothing
+	// should be forwarded).
+	mouseCapture func(event *MouseEvent) *MouseEvent
+
+	// The position of the box in the application's tab order (see
+	// SetTabIndex()).
+	tabIndex int
+
 	// An optional function which is called before the box is drawn.
 	draw func(screen tcell.Screen, x, y, width, height int) (int, int, int, int)
 }
@@ -106,6 +119,20 @@ func (b *Box) GetInnerRect() (int, int, int, int) {
 		height - b.paddingTop - b.paddingBottom
 }
 
+// InRect returns true if the given coordinate is within the bounds of the box's
+// rectangle.
+func (b *Box) InRect(x, y int) bool {
+	rectX, rectY, width, height := b.GetRect()
+	return x >= rectX && x < rectX+width && y >= rectY && y < rectY+height
+}
+
+// InInnerRect returns true if the given coordinate is within the bounds of the
+// box's inner rectangle, i.e. not on its border or padding.
+func (b *Box) InInnerRect(x, y int) bool {
+	rectX, rectY, width, height := b.GetInnerRect()
+	return x >= rectX && x < rectX+width && y >= rectY && y < rectY+height
+}
+
 // SetRect sets a new position of the primitive. Note that this has no effect
 // if this primitive is part of a layout (e.g. Flex, Grid) or if it was added
 // like this:
@@ -184,6 +211,50 @@ func (b *Box) GetInputCapture() func(event *tcell.EventKey) *tcell.EventKey {
 	return b.inputCapture
 }
 
+// SetMouseCapture installs a function which captures mouse events before they
+// are forwarded to the primitive under the mouse pointer. Unlike key events,
+// mouse events travel through all primitives containing the pointer: First,
+// the capture functions are called from the application's root primitive down
+// to the primitive under the pointer. Then, the mouse handlers (see
+// MouseEventSupport) are called in the opposite direction, starting at the
+// primitive under the pointer and bubbling up through its containers until one
+// of them consumes the event.
+//
+// The capture function can choose to forward the mouse event (or a different
+// one) by returning it or stop the event processing by returning nil. This
+// also works for primitives composed of other primitives, such as Form, Flex,
+// or Grid.
+//
+// Providing a nil handler will remove a previously existing handler.
+func (b *Box) SetMouseCapture(capture func(event *MouseEvent) *MouseEvent) *Box {
+	b.mouseCapture = capture
+	return b
+}
+
+// GetMouseCapture returns the function installed with SetMouseCapture() or nil
+// if no such function has been installed.
+func (b *Box) GetMouseCapture() func(event *MouseEvent) *MouseEvent {
+	return b.mouseCapture
+}
+
+// SetTabIndex sets the position of the box in the tab order, i.e. the order in
+// which Application.FocusNext() and Application.FocusPrevious() move the focus
+// between primitives. Primitives with an index of 0 (the default) are visited
+// in the order in which they appear in the layout. Primitives with a positive
+// index are visited before them, in ascending order of their index. Primitives
+// with a negative index are skipped by keyboard navigation but may still be
+// focused with SetFocus() or with the mouse.
+func (b *Box) SetTabIndex(index int) *Box {
+	b.tabIndex = index
+	return b
+}
+
+// GetTabIndex returns the position of the box in the tab order. See
+// SetTabIndex() for details.
+func (b *Box) GetTabIndex() int {
+	return b.tabIndex
+}
+
 // SetBackgroundColor sets the box's background color.
 func (b *Box) SetBackgroundColor(color tcell.Color) *Box {
 	b.backgroundColor = color
@@ -335,6 +406,36 @@ func (b *Box) HasFocus() bool {
 	return b.hasFocus
 }
 
+// SetFocusFunc sets a callback function which is invoked when this primitive
+// or one of its descendants receives focus while none of them had it before.
+// It is invoked by the application once the focus has settled (see
+// Application.SetFocus()), so moving the focus between the children of a
+// container does not invoke the container's callback.
+func (b *Box) SetFocusFunc(callback func()) *Box {
+	b.focusFunc = callback
+	return b
+}
+
+// GetFocusFunc returns the callback function installed with SetFocusFunc() or
+// nil if none has been installed.
+func (b *Box) GetFocusFunc() func() {
+	return b.focusFunc
+}
+
+// SetBlurFunc sets a callback function which is invoked when this primitive
+// and its descendants lose focus, i.e. when the focus moves to a primitive
+// outside of this primitive's subtree. See SetFocusFunc() for details.
+func (b *Box) SetBlurFunc(callback func()) *Box {
+	b.blurFunc = callback
+	return b
+}
+
+// GetBlurFunc returns the callback function installed with SetBlurFunc() or
+// nil if none has been installed.
+func (b *Box) GetBlurFunc() func() {
+	return b.blurFunc
+}
+
 // GetFocusable returns the item's Focusable.
 func (b *Box) GetFocusable() Focusable {
 	return b.focus
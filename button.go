@@ -27,7 +27,7 @@ type Button struct {
 
 	// An optional function which is called when the user leaves the button. A
 	// key is provided indicating which key was pressed to leave (tab or backtab).
-	blur func(tcell.Key)
+	exit func(tcell.Key)
 }
 
 // NewButton returns a new input field.
@@ -80,18 +80,29 @@ func (b *Button) SetSelectedFunc(handler func()) *Button {
 	return b
 }
 
-// SetBlurFunc sets a handler which is called when the user leaves the button.
+// SetExitFunc sets a handler which is called when the user leaves the button.
 // The callback function is provided with the key that was pressed, which is one
 // of the following:
 //
 //   - KeyEscape: Leaving the button with no specific direction.
 //   - KeyTab: Move to the next field.
 //   - KeyBacktab: Move to the previous field.
-func (b *Button) SetBlurFunc(handler func(key tcell.Key)) *Button {
-	b.blur = handler
+//
+// To be notified whenever the button loses focus, use Box.SetBlurFunc() instead.
+func (b *Button) SetExitFunc(handler func(key tcell.Key)) *Button {
+	b.exit = handler
 	return b
 }
 
+// SetBlurFunc sets a handler which is called when the user leaves the button.
+// It shadows Box.SetBlurFunc(), which remains available as
+// button.Box.SetBlurFunc().
+//
+// Deprecated: Use SetExitFunc() instead.
+func (b *Button) SetBlurFunc(handler func(key tcell.Key)) *Button {
+	return b.SetExitFunc(handler)
+}
+
 // Draw draws this primitive onto the screen.
 func (b *Button) Draw(screen tcell.Screen) {
 	// Draw the box.
@@ -119,6 +130,13 @@ func (b *Button) Draw(screen tcell.Screen) {
 	}
 }
 
+// ConsumesKey returns whether or not the button uses the given key itself. This
+// is the case for Tab and Backtab if a handler was installed with
+// SetExitFunc().
+func (b *Button) ConsumesKey(event *tcell.EventKey) bool {
+	return isTabKey(event) && b.exit != nil
+}
+
 // InputHandler returns the handler for this primitive.
 func (b *Button) InputHandler() func(event *tcell.EventKey, setFocus func(p Primitive)) {
 	return b.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p Primitive)) {
@@ -129,9 +147,25 @@ func (b *Button) InputHandler() func(event *tcell.EventKey, setFocus func(p Prim
 				b.selected()
 			}
 		case tcell.KeyBacktab, tcell.KeyTab, tcell.KeyEscape: // Leave. No action.
-			if b.blur != nil {
-				b.blur(key)
+			if b.exit != nil {
+				b.exit(key)
 			}
 		}
 	})
 }
+
+// MouseHandler returns the mouse handler for this primitive. Clicking the
+// button selects it.
+func (b *Button) MouseHandler() func(event *MouseEvent) bool {
+	return func(event *MouseEvent) bool {
+		if event.Action != MouseClick || event.Button != tcell.Button1 {
+			return false
+		}
+
+		if b.selected != nil {
+			b.selected()
+		}
+
+		return true
+	}
+}
@@ -178,6 +178,13 @@ func (c *Checkbox) Draw(screen tcell.Screen) {
 	screen.SetContent(x, y, checkedRune, nil, fieldStyle)
 }
 
+// ConsumesKey returns whether or not the checkbox uses the given key itself.
+// This is the case for Tab and Backtab if a handler was installed with
+// SetDoneFunc() or if the checkbox is part of a form.
+func (c *Checkbox) ConsumesKey(event *tcell.EventKey) bool {
+	return isTabKey(event) && (c.done != nil || c.finished != nil)
+}
+
 // InputHandler returns the handler for this primitive.
 func (c *Checkbox) InputHandler() func(event *tcell.EventKey, setFocus func(p Primitive)) {
 	return c.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p Primitive)) {
@@ -201,3 +208,20 @@ func (c *Checkbox) InputHandler() func(event *tcell.EventKey, setFocus func(p Pr
 		}
 	})
 }
+
+// MouseHandler returns the mouse handler for this primitive. Clicking the
+// checkbox or its label toggles its state.
+func (c *Checkbox) MouseHandler() func(event *MouseEvent) bool {
+	return func(event *MouseEvent) bool {
+		if event.Action != MouseClick || event.Button != tcell.Button1 {
+			return false
+		}
+
+		c.checked = !c.checked
+		if c.changed != nil {
+			c.changed(c.checked)
+		}
+
+		return true
+	}
+}
@@ -0,0 +1,99 @@
+package tview
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// The names of the actions in an application's default keymap which copy to
+// and paste from the clipboard (see Application.Copy() and
+// Application.Paste()). They are not bound to any keys by default. To use
+// Ctrl-C for copying instead of quitting:
+//
+//   keymap := app.GetKeymap()
+//   keymap.Unbind(tview.QuitAction, "Ctrl-C")
+//   keymap.Bind(tview.QuitAction, "Ctrl-Q")
+//   keymap.Bind(tview.CopyAction, "Ctrl-C")
+//   keymap.Bind(tview.PasteAction, "Ctrl-V")
+const (
+	CopyAction  = "copy"
+	PasteAction = "paste"
+)
+
+// The escape sequence which asks the terminal to store base64-encoded text in
+// the system clipboard (OSC 52).
+const clipboardSet = "\x1b]52;c;%s\x07"
+
+// addClipboardActions adds the clipboard actions to the keymap.
+func (a *Application) addClipboardActions(keymap *Keymap) {
+	keymap.
+		SetAction(CopyAction, "Copy to the clipboard", nil, a.Copy).
+		SetAction(PasteAction, "Paste from the clipboard", nil, a.Paste)
+}
+
+// SetClipboard stores the given text in the application's clipboard. If the
+// screen supports sending escape sequences to the terminal, the terminal is
+// also asked to store it in the system clipboard (using OSC 52). This works
+// over SSH connections and, when running inside tmux, is passed through to the
+// terminal running tmux. Not all terminals support this, and some only do so
+// when configured accordingly.
+func (a *Application) SetClipboard(text string) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.clipboard = text
+	if a.Screen == nil || !a.escapeCapable {
+		return
+	}
+	terminal, ok := a.Screen.(interface{ TPuts(string) })
+	if !ok {
+		return
+	}
+
+	sequence := fmt.Sprintf(clipboardSet, base64.StdEncoding.EncodeToString([]byte(text)))
+	if _, stream := a.Screen.(*StreamScreen); !stream && os.Getenv("TMUX") != "" {
+		// Wrap the sequence in a tmux passthrough sequence.
+		sequence = "\x1bPtmux;" + strings.Replace(sequence, "\x1b", "\x1b\x1b", -1) + "\x1b\\"
+	}
+	terminal.TPuts(sequence)
+}
+
+// GetClipboard returns the text stored with SetClipboard(). The system
+// clipboard is not queried as most terminals don't allow applications to read
+// it. Text pasted into the terminal arrives as an EventPaste instead.
+func (a *Application) GetClipboard() string {
+	a.RLock()
+	defer a.RUnlock()
+
+	return a.clipboard
+}
+
+// Copy copies the content or the selection of the primitive which has focus to
+// the clipboard (see SetClipboard()), provided that it implements CopySupport
+// and has something to copy. Among the built-in widgets, InputField copies its
+// text, Table its selected cell, row, or column, and TextView its selected
+// text or highlighted regions.
+func (a *Application) Copy() {
+	a.RLock()
+	p := a.focus
+	a.RUnlock()
+
+	if copySupport, ok := p.(CopySupport); ok {
+		if handler := copySupport.CopyHandler(); handler != nil {
+			if text := handler(); text != "" {
+				a.SetClipboard(text)
+			}
+		}
+	}
+}
+
+// Paste hands the text stored in the clipboard to the primitive which has
+// focus as if it was pasted into the terminal (see EventPaste). This function
+// must be called from the event loop, e.g. from a key binding.
+func (a *Application) Paste() {
+	if text := a.GetClipboard(); text != "" {
+		a.handlePaste(NewEventPaste(text))
+	}
+}
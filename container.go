@@ -0,0 +1,66 @@
+package tview
+
+// Container is implemented by primitives which contain other primitives, such
+// as Flex, Grid, or Pages. The application uses it to walk the tree of
+// primitives, e.g. to find the primitive under the mouse pointer. Custom
+// layouts should implement it, too, so they behave like the built-in ones.
+type Container interface {
+	// Children returns the contained primitives which are currently part of the
+	// layout, in the order in which they are drawn. Primitives drawn last are
+	// considered to be on top of the ones drawn before them.
+	Children() []Primitive
+}
+
+// getComponentPath returns the path from the given primitive down to the
+// highest level component at the given coordinates or nil if no component can
+// be found.
+func getComponentPath(primitive Primitive, x, y int) []Primitive {
+	if primitive == nil {
+		return nil
+	}
+
+	// Children drawn last are on top.
+	if container, ok := primitive.(Container); ok {
+		children := container.Children()
+		for index := len(children) - 1; index >= 0; index-- {
+			if path := getComponentPath(children[index], x, y); path != nil {
+				return append([]Primitive{primitive}, path...)
+			}
+		}
+	}
+
+	if inRect(primitive, x, y) {
+		return []Primitive{primitive}
+	}
+
+	return nil
+}
+
+// getPrimitivePath returns the path from the given root primitive down to the
+// target primitive or nil if the target is not part of the root's tree.
+func getPrimitivePath(root, target Primitive) []Primitive {
+	if root == nil {
+		return nil
+	}
+
+	if root == target {
+		return []Primitive{root}
+	}
+
+	if container, ok := root.(Container); ok {
+		for _, child := range container.Children() {
+			if path := getPrimitivePath(child, target); path != nil {
+				return append([]Primitive{root}, path...)
+			}
+		}
+	}
+
+	return nil
+}
+
+// inRect returns true if the given coordinate is within the bounds of the
+// primitive's rectangle.
+func inRect(primitive Primitive, x, y int) bool {
+	componentX, componentY, width, height := primitive.GetRect()
+	return x >= componentX && x < componentX+width && y >= componentY && y < componentY+height
+}
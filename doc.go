@@ -49,7 +49,7 @@ world!":
 First, we create a box primitive with a border and a title. Then we create an
 application, set the box as its root primitive, and run the event loop. The
 application exits when the application's Stop() function is called or when
-Ctrl-C is pressed.
+Ctrl-C is pressed (see "Key Bindings" below).
 
 If we have a primitive which consumes key presses, we call the application's
 SetFocus() function to redirect all key presses to that primitive. Most
@@ -113,6 +113,15 @@ Examples:
   [:]No effect
   []Not a valid color tag, will print square brackets as they are
 
+TextView also accepts a fourth field with the target of a hyperlink if this is
+enabled with TextView.SetHyperlinks(). Hyperlinks can be opened from terminals
+which support them (using the OSC 8 escape sequence) but are only emitted by
+StreamScreen, including inline screens (see below). Other screens show the
+text without them. A hyperlink ends with a tag whose fourth field is empty or
+a dash:
+
+  See the [:::https://github.com/rivo/tview]tview repository[:::-] for more.
+
 In the rare event that you want to display a string such as "[red]" or
 "[#00ff1a]" without applying its effect, you need to put an opening square
 bracket before the closing square bracket. Note that the text inside the
@@ -147,7 +156,9 @@ will execute in the main goroutine and thus will not cause any race conditions.
 If you access your primitives from other goroutines, however, you will need to
 synchronize execution. The easiest way to do this is to call
 Application.QueueUpdate() or Application.QueueUpdateDraw() (see the function
-documentation for details):
+documentation for details). The functions are executed on the main goroutine,
+between events. Application.QueueUpdateWait() also waits for the function to
+finish:
 
   go func() {
     app.QueueUpdateDraw(func() {
@@ -164,17 +175,168 @@ it in QueueUpdate(). And, as mentioned above, key event callbacks are executed
 in the main goroutine and thus should not use QueueUpdate() as that may lead to
 deadlocks.
 
+Timers and Animation
+
+Instead of calling Application.Draw() from goroutines driven by a time.Ticker,
+use Application.AfterFunc() and Application.Every(). Their functions are
+called on the event loop, so they may access primitives directly, and the
+screen is redrawn afterwards. Application.Animate() calls a function on every
+frame during a given duration with the animation's progress, shaped by an
+easing function such as EaseInOutQuad(). Timers may be stopped and tied to a
+primitive so that they pause while it is hidden:
+
+  spinner := app.Every(100*time.Millisecond, func() {
+    frame = (frame + 1) % len(frames)
+    textView.SetText(frames[frame])
+  }).SetPrimitive(textView)
+  defer spinner.Stop()
+
 Type Hierarchy
 
 All widgets listed above contain the Box type. All of Box's functions are
 therefore available for all widgets, too.
 
 All widgets also implement the Primitive interface. There is also the Focusable
-interface which is used to override functions in subclassing types.
+interface which is used to override functions in subclassing types. Layouts
+which contain other primitives (e.g. Flex, Grid, Pages, Frame, Form, Modal)
+implement the Container interface so the application can find the primitives
+they contain. Custom layouts should implement it, too.
 
 The tview package is based on https://github.com/diamondburned/tcell. It uses types
 and constants from that package (e.g. colors and keyboard values).
 
-This package does not process mouse input (yet).
+Mouse Input
+
+When mouse support is enabled (Application.MouseSupport, the default), the
+application turns the raw mouse events of the terminal into mouse actions such
+as clicks, double clicks, drags, and hover events (see MouseEvent). These are
+forwarded to the primitive under the pointer if it implements the
+MouseEventSupport interface. While a button is held down, the primitive which
+received the press keeps receiving the events. All built-in widgets implement
+MouseEventSupport: clicking selects list items, table cells and tree nodes,
+presses buttons, toggles checkboxes, opens drop-downs and moves the cursor of
+input fields. The mouse wheel scrolls lists, tables, tree views and scrollable
+text views.
+
+Primitives implementing the MouseSupport interface instead receive the raw
+tcell mouse events, without the pointer capture described above.
+
+Mouse events are first passed through the capture functions installed with
+Box.SetMouseCapture(), from the root primitive down to the primitive under the
+pointer. They are then handed to the mouse handler of that primitive and, if it
+doesn't consume them, bubble up through its containers (e.g. Form, Frame, Flex).
+
+Mouse events do not move the keyboard focus by default. Call
+Application.SetClickToFocus() to focus primitives when they are clicked, or
+Application.SetFocusFollowsMouse() to have the focus follow the pointer.
+
+Key Bindings
+
+A Keymap binds keys to named actions. Each application has one, returned by
+Application.GetKeymap(). Bindings may be single keys ("Ctrl-S") or chords
+("g g", "Ctrl-X Ctrl-S"). Actions are either global or scoped to a primitive,
+in which case they are only active while that primitive or one of its
+descendants has focus, and take precedence over global bindings of the same
+keys. The keymap sees key events after the application's input capture function
+and before the focused primitive:
+
+  keymap := app.GetKeymap().
+  	SetAction("save", "Save the file", nil, save).
+  	SetAction("top", "Go to the top", textView, scrollToTop)
+  keymap.Bind("save", "Ctrl-S", "Ctrl-X Ctrl-S")
+  keymap.Bind("top", "g g")
+
+The default keymap binds Ctrl-C to the "quit" action (QuitAction), which calls
+Application.Quit(). It may be rebound or removed like any other action, e.g. to
+use Ctrl-C for copying. Quit() first calls the function installed with
+Application.SetBeforeQuitFunc(), which may veto the request, e.g. to ask
+whether unsaved changes should be discarded. Application.SetShutdownFunc()
+installs a function which is called when the application has stopped.
+
+Users may override the default bindings with Keymap.LoadBindings(), which reads
+them from a JSON file. Application.ActiveBindings() lists the bindings which
+are currently active, e.g. for a help screen.
+
+Focus Navigation
+
+The application can move the keyboard focus between all primitives of the
+layout: Application.FocusNext() and Application.FocusPrevious() walk the tree
+of primitives (see Container) and visit each primitive which accepts key
+events, including the items and buttons of forms. Box.SetTabIndex() changes the
+order or excludes primitives. Application.FocusLeft(), FocusRight(), FocusUp(),
+and FocusDown() move the focus to the nearest primitive on the screen in the
+given direction. These functions are available as keymap actions. Tab and
+Backtab are bound to FocusNext() and FocusPrevious() by default, except while
+the focused primitive uses these keys itself (see KeyConsumer), e.g. a List to
+move between its items, an InputField to select autocomplete suggestions, or
+the items of a Form to move within the form. The directional actions are not
+bound by default:
+
+  keymap := app.GetKeymap()
+  keymap.Bind(tview.FocusLeftAction, "Alt-Left")
+  keymap.Bind(tview.FocusRightAction, "Alt-Right")
+
+While a modal dialog is shown, Application.PushFocusTrap() keeps the focus
+inside it. Application.PopFocusTrap() returns the focus to where it was.
+
+Box.SetFocusFunc() and Box.SetBlurFunc() install callbacks which are invoked
+when the focus enters or leaves a primitive or any of its descendants, e.g. to
+load data when a pane is entered or to save a draft when it is left.
+Application.SetFocusChangedFunc() is notified of every change of the focus.
+
+Pasting Text
+
+The application enables bracketed paste mode if the terminal supports it. Text
+pasted into the terminal then arrives as a single EventPaste instead of many
+key events. It is handed to the focused primitive if it implements the
+PasteSupport interface. InputField inserts pasted text at the cursor position
+in one step, replacing line breaks with spaces, so that the acceptance function
+and the "changed" callback are only called once and embedded line breaks don't
+finish the input. Primitives without paste support receive the text as key
+events.
+
+Clipboard
+
+Application.SetClipboard() stores text in the application's clipboard and asks
+the terminal to put it into the system clipboard (using the OSC 52 escape
+sequence, which also works over SSH and inside tmux if the terminal supports
+it). Application.Copy() copies from the focused primitive if it implements the
+CopySupport interface: InputField copies its text, Table its selection, and
+TextView its selected text or highlighted regions. Application.Paste() pastes
+the clipboard into the focused primitive like text pasted into the terminal.
+Both are available as keymap actions (CopyAction and PasteAction) which are not
+bound by default.
+
+Terminal Window
+
+Application.SetTerminalFocusFunc() installs a callback which is invoked when
+the terminal window running the application receives or loses focus, e.g. to
+pause animations. Application.SetTitle() sets the title of the terminal window.
+The previous title is restored when the application stops or is suspended.
+Both require a terminal which supports them.
+
+Application.Suspend() leaves terminal UI mode temporarily, e.g. to run an
+editor, and restores the application's terminal configuration afterwards. On
+Unix systems, the SIGTSTP signal suspends the application as a background job
+until it is continued. So does the "suspend" action (SuspendAction), which is
+not bound by default:
+
+  app.GetKeymap().Bind(tview.SuspendAction, "Ctrl-Z")
+
+Inline Mode
+
+Instead of taking over the whole terminal, an application may render into a
+few lines at the cursor's position, e.g. to use a Form, List, or DropDown as an
+interactive prompt in a command line tool. Hand a screen returned by
+NewInlineScreen() to Application.SetScreen(). The screen grows as needed and,
+when the application stops, its last frame remains on the terminal.
+
+Serving Applications over a Network
+
+Applications are independent of each other, so one process may run many of
+them, e.g. one per SSH session. A StreamScreen drives a terminal over any
+io.ReadWriter, such as an SSH channel, and is handed to the application with
+Application.SetScreen(). Report the terminal's size with StreamScreen.SetSize()
+whenever it changes. The application stops when the stream ends.
 */
 package tview
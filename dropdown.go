@@ -80,6 +80,9 @@ type DropDown struct {
 	// A callback function which is called when the user changes the drop-down's
 	// selection.
 	selected func(text string, index int)
+
+	// The option which was selected before the list was opened.
+	optionBefore int
 }
 
 // NewDropDown returns a new drop-down.
@@ -104,6 +107,17 @@ func NewDropDown() *DropDown {
 
 	d.focus = d
 
+	// A single click selects an option right away.
+	list.SetMouseCapture(func(event *MouseEvent) *MouseEvent {
+		switch event.Action {
+		case MouseClick:
+			event.Action = MouseDoubleClick
+		case MouseDoubleClick:
+			return nil // Already selected with the first click.
+		}
+		return event
+	})
+
 	return d
 }
 
@@ -376,25 +390,16 @@ func (d *DropDown) Draw(screen tcell.Screen) {
 	}
 }
 
+// ConsumesKey returns whether or not the drop-down uses the given key itself.
+// This is the case for Tab and Backtab if a handler was installed with
+// SetDoneFunc() or if the drop-down is part of a form.
+func (d *DropDown) ConsumesKey(event *tcell.EventKey) bool {
+	return isTabKey(event) && (d.done != nil || d.finished != nil)
+}
+
 // InputHandler returns the handler for this primitive.
 func (d *DropDown) InputHandler() func(event *tcell.EventKey, setFocus func(p Primitive)) {
 	return d.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p Primitive)) {
-		// A helper function which selects an item in the drop-down list based on
-		// the current prefix.
-		evalPrefix := func() {
-			if len(d.prefix) > 0 {
-				for index, option := range d.options {
-					if strings.HasPrefix(strings.ToLower(option.Text), d.prefix) {
-						d.list.SetCurrentItem(index)
-						return
-					}
-				}
-				// Prefix does not match any item. Remove last rune.
-				r := []rune(d.prefix)
-				d.prefix = string(r[:len(r)-1])
-			}
-		}
-
 		// Process key event.
 		switch key := event.Key(); key {
 		case tcell.KeyEnter, tcell.KeyRune, tcell.KeyDown:
@@ -403,45 +408,10 @@ func (d *DropDown) InputHandler() func(event *tcell.EventKey, setFocus func(p Pr
 			// If the first key was a letter already, it becomes part of the prefix.
 			if r := event.Rune(); key == tcell.KeyRune && r != ' ' {
 				d.prefix += string(r)
-				evalPrefix()
+				d.evalPrefix()
 			}
 
-			// Hand control over to the list.
-			d.open = true
-			optionBefore := d.currentOption
-			d.list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
-				// An option was selected. Close the list again.
-				d.open = false
-				setFocus(d)
-				d.currentOption = index
-
-				// Trigger "selected" event.
-				if d.selected != nil {
-					d.selected(d.options[d.currentOption].Text, d.currentOption)
-				}
-				if d.options[d.currentOption].Selected != nil {
-					d.options[d.currentOption].Selected()
-				}
-			}).SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-				if event.Key() == tcell.KeyRune {
-					d.prefix += string(event.Rune())
-					evalPrefix()
-				} else if event.Key() == tcell.KeyBackspace || event.Key() == tcell.KeyBackspace2 {
-					if len(d.prefix) > 0 {
-						r := []rune(d.prefix)
-						d.prefix = string(r[:len(r)-1])
-					}
-					evalPrefix()
-				} else if event.Key() == tcell.KeyEscape {
-					d.open = false
-					d.currentOption = optionBefore
-					setFocus(d)
-				} else {
-					d.prefix = ""
-				}
-				return event
-			})
-			setFocus(d.list)
+			d.openList(setFocus)
 		case tcell.KeyEscape, tcell.KeyTab, tcell.KeyBacktab:
 			if d.done != nil {
 				d.done(key)
@@ -453,6 +423,88 @@ func (d *DropDown) InputHandler() func(event *tcell.EventKey, setFocus func(p Pr
 	})
 }
 
+// evalPrefix selects an item in the drop-down list based on the current
+// prefix.
+func (d *DropDown) evalPrefix() {
+	if len(d.prefix) > 0 {
+		for index, option := range d.options {
+			if strings.HasPrefix(strings.ToLower(option.Text), d.prefix) {
+				d.list.SetCurrentItem(index)
+				return
+			}
+		}
+		// Prefix does not match any item. Remove last rune.
+		r := []rune(d.prefix)
+		d.prefix = string(r[:len(r)-1])
+	}
+}
+
+// openList hands control over to the drop-down list, making the options
+// visible and selectable.
+func (d *DropDown) openList(setFocus func(p Primitive)) {
+	d.open = true
+	d.optionBefore = d.currentOption
+	d.list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		// An option was selected. Close the list again.
+		d.open = false
+		setFocus(d)
+		d.currentOption = index
+
+		// Trigger "selected" event.
+		if d.selected != nil {
+			d.selected(d.options[d.currentOption].Text, d.currentOption)
+		}
+		if d.options[d.currentOption].Selected != nil {
+			d.options[d.currentOption].Selected()
+		}
+	}).SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyRune {
+			d.prefix += string(event.Rune())
+			d.evalPrefix()
+		} else if event.Key() == tcell.KeyBackspace || event.Key() == tcell.KeyBackspace2 {
+			if len(d.prefix) > 0 {
+				r := []rune(d.prefix)
+				d.prefix = string(r[:len(r)-1])
+			}
+			d.evalPrefix()
+		} else if event.Key() == tcell.KeyEscape {
+			d.closeList(setFocus)
+		} else {
+			d.prefix = ""
+		}
+		return event
+	})
+	setFocus(d.list)
+}
+
+// closeList hides the drop-down list without selecting an option, restoring
+// the previously selected option and returning the focus to the drop-down.
+func (d *DropDown) closeList(setFocus func(p Primitive)) {
+	d.open = false
+	d.currentOption = d.optionBefore
+	setFocus(d)
+}
+
+// MouseHandler returns the mouse handler for this primitive. Clicking the
+// drop-down opens the list of options, clicking it again closes the list.
+// Options are selected by clicking them in the list.
+func (d *DropDown) MouseHandler() func(event *MouseEvent) bool {
+	return func(event *MouseEvent) bool {
+		if event.Action != MouseClick || event.Button != tcell.Button1 {
+			return false
+		}
+
+		if d.open {
+			d.closeList(event.SetFocus)
+		} else {
+			d.prefix = ""
+			d.openList(event.SetFocus)
+		}
+
+		return true
+	}
+}
+
 // Focus is called by the application when the primitive receives focus.
 func (d *DropDown) Focus(delegate func(p Primitive)) {
 	d.Box.Focus(delegate)
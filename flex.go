@@ -172,6 +172,18 @@ func (f *Flex) Draw(screen tcell.Screen) {
 	}
 }
 
+// Children returns the primitives contained in the flex container. Empty
+// items are skipped.
+func (f *Flex) Children() []Primitive {
+	children := make([]Primitive, 0, len(f.items))
+	for _, item := range f.items {
+		if item.Item != nil {
+			children = append(children, item.Item)
+		}
+	}
+	return children
+}
+
 // Focus is called when this primitive receives focus.
 func (f *Flex) Focus(delegate func(p Primitive)) {
 	for _, item := range f.items {
@@ -0,0 +1,352 @@
+package tview
+
+import "sort"
+
+// The names of the actions in an application's default keymap which move the
+// focus between primitives (see Application.FocusNext() and others). Tab and
+// Backtab are bound to FocusNextAction and FocusPreviousAction by default.
+// They are not triggered while the focused primitive uses these keys itself
+// (see KeyConsumer), e.g. in a List or a Form. The other actions are not bound
+// to any keys by default:
+//
+//   keymap := app.GetKeymap()
+//   keymap.Bind(tview.FocusLeftAction, "Alt-Left")
+//   keymap.Bind(tview.FocusRightAction, "Alt-Right")
+const (
+	FocusNextAction     = "focus-next"
+	FocusPreviousAction = "focus-previous"
+	FocusLeftAction     = "focus-left"
+	FocusRightAction    = "focus-right"
+	FocusUpAction       = "focus-up"
+	FocusDownAction     = "focus-down"
+)
+
+// focusTrap is a primitive which the keyboard focus may not leave.
+type focusTrap struct {
+	// The primitive whose subtree the focus is restricted to.
+	trap Primitive
+
+	// The primitive which had focus when the trap was installed.
+	previous Primitive
+}
+
+// addFocusActions adds the focus navigation actions to the keymap.
+func (a *Application) addFocusActions(keymap *Keymap) {
+	keymap.
+		SetAction(FocusNextAction, "Move the focus to the next primitive", nil, a.FocusNext).
+		SetAction(FocusPreviousAction, "Move the focus to the previous primitive", nil, a.FocusPrevious).
+		SetAction(FocusLeftAction, "Move the focus to the primitive on the left", nil, a.FocusLeft).
+		SetAction(FocusRightAction, "Move the focus to the primitive on the right", nil, a.FocusRight).
+		SetAction(FocusUpAction, "Move the focus to the primitive above", nil, a.FocusUp).
+		SetAction(FocusDownAction, "Move the focus to the primitive below", nil, a.FocusDown)
+}
+
+// FocusNext moves the focus to the next primitive in the tab order (see
+// Box.SetTabIndex()). The tab order covers all primitives which are currently
+// part of the layout and which do not contain other primitives, found by
+// walking the tree of primitives starting at the root primitive (see
+// Container). After the last primitive, the focus moves to the first one
+// again. If a focus trap is installed, only the primitives inside the trap
+// are visited (see PushFocusTrap()).
+func (a *Application) FocusNext() {
+	a.focusTabOrder(1)
+}
+
+// FocusPrevious moves the focus to the previous primitive in the tab order.
+// See FocusNext() for details.
+func (a *Application) FocusPrevious() {
+	a.focusTabOrder(-1)
+}
+
+// FocusLeft moves the focus to the nearest primitive on the screen which is
+// located to the left of the primitive which currently has focus. Primitives
+// are considered in the same way as by FocusNext().
+func (a *Application) FocusLeft() {
+	a.focusDirection(-1, 0)
+}
+
+// FocusRight moves the focus to the nearest primitive on the screen which is
+// located to the right of the primitive which currently has focus.
+func (a *Application) FocusRight() {
+	a.focusDirection(1, 0)
+}
+
+// FocusUp moves the focus to the nearest primitive on the screen which is
+// located above the primitive which currently has focus.
+func (a *Application) FocusUp() {
+	a.focusDirection(0, -1)
+}
+
+// FocusDown moves the focus to the nearest primitive on the screen which is
+// located below the primitive which currently has focus.
+func (a *Application) FocusDown() {
+	a.focusDirection(0, 1)
+}
+
+// PushFocusTrap restricts keyboard focus navigation to the given primitive and
+// its descendants, e.g. to a modal dialog. If the focus is currently outside
+// the trap, it is moved to the first primitive inside the trap. Clicking
+// primitives outside the trap does not move the focus to them (see
+// SetClickToFocus()). Traps may be nested; the most recently pushed trap is
+// in effect.
+func (a *Application) PushFocusTrap(trap Primitive) {
+	a.Lock()
+	a.focusTraps = append(a.focusTraps, focusTrap{trap: trap, previous: a.focus})
+	root, focus := a.root, a.focus
+	a.Unlock()
+
+	if focus == nil || getPrimitivePath(trap, focus) == nil {
+		if candidates := getFocusCandidates(trap); len(candidates) > 0 {
+			a.focusPrimitive(root, candidates[0])
+		} else {
+			a.SetFocus(trap)
+		}
+	}
+}
+
+// PopFocusTrap removes the focus trap installed last with PushFocusTrap() and
+// returns the focus to the primitive which had it when the trap was installed.
+func (a *Application) PopFocusTrap() {
+	a.Lock()
+	if len(a.focusTraps) == 0 {
+		a.Unlock()
+		return
+	}
+	trap := a.focusTraps[len(a.focusTraps)-1]
+	a.focusTraps = a.focusTraps[:len(a.focusTraps)-1]
+	root := a.root
+	a.Unlock()
+
+	if trap.previous != nil {
+		if !a.focusPrimitive(root, trap.previous) && !trap.previous.GetFocusable().HasFocus() {
+			a.SetFocus(trap.previous)
+		}
+	}
+}
+
+// GetFocusTrap returns the focus trap currently in effect or nil if there is
+// none.
+func (a *Application) GetFocusTrap() Primitive {
+	a.RLock()
+	defer a.RUnlock()
+
+	if len(a.focusTraps) == 0 {
+		return nil
+	}
+	return a.focusTraps[len(a.focusTraps)-1].trap
+}
+
+// focusScope returns the root primitive, the primitive whose subtree keyboard
+// navigation is restricted to, and the primitive which has focus.
+func (a *Application) focusScope() (root, scope, focus Primitive) {
+	a.RLock()
+	defer a.RUnlock()
+
+	root, scope, focus = a.root, a.root, a.focus
+	if len(a.focusTraps) > 0 {
+		scope = a.focusTraps[len(a.focusTraps)-1].trap
+	}
+	return
+}
+
+// focusTabOrder moves the focus by the given number of steps in the tab order.
+func (a *Application) focusTabOrder(step int) {
+	root, scope, focus := a.focusScope()
+	candidates := getFocusCandidates(scope)
+	if len(candidates) == 0 {
+		return
+	}
+
+	// Sort by tab index, positive indexes first.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ti, tj := getTabIndex(candidates[i]), getTabIndex(candidates[j])
+		if ti == 0 || tj == 0 {
+			return ti != 0 && tj == 0
+		}
+		return ti < tj
+	})
+
+	next := 0
+	if step < 0 {
+		next = len(candidates) - 1
+	}
+	for index, candidate := range candidates {
+		if candidate == focus {
+			next = (index + step + len(candidates)) % len(candidates)
+			break
+		}
+	}
+
+	a.focusPrimitive(root, candidates[next])
+}
+
+// focusDirection moves the focus to the nearest primitive in the given
+// direction.
+func (a *Application) focusDirection(dx, dy int) {
+	root, scope, focus := a.focusScope()
+	if focus == nil {
+		a.focusTabOrder(1)
+		return
+	}
+	fx, fy, fw, fh := focus.GetRect()
+
+	var (
+		best      Primitive
+		bestScore int
+	)
+	for _, candidate := range getFocusCandidates(scope) {
+		if candidate == focus {
+			continue
+		}
+		cx, cy, cw, ch := candidate.GetRect()
+
+		// The distance along the direction of movement and the offset across
+		// it.
+		var distance, offset int
+		switch {
+		case dx < 0:
+			distance, offset = fx-(cx+cw), rangeGap(fy, fh, cy, ch)
+		case dx > 0:
+			distance, offset = cx-(fx+fw), rangeGap(fy, fh, cy, ch)
+		case dy < 0:
+			distance, offset = fy-(cy+ch), rangeGap(fx, fw, cx, cw)
+		default:
+			distance, offset = cy-(fy+fh), rangeGap(fx, fw, cx, cw)
+		}
+		if distance < 0 {
+			continue // Not in this direction.
+		}
+
+		// Prefer primitives which are aligned with the focused one.
+		score := distance + 2*offset
+		if best == nil || score < bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+
+	if best != nil {
+		a.focusPrimitive(root, best)
+	}
+}
+
+// focusPrimitive moves the focus to the given primitive, telling the
+// containers on the way from the root primitive which of their children is
+// supposed to be focused (see focusPath()). Returns true if the focus was
+// changed.
+func (a *Application) focusPrimitive(root, p Primitive) bool {
+	path := getPrimitivePath(root, p)
+	if path == nil {
+		return false
+	}
+	return a.focusPath(path)
+}
+
+// inFocusTrap returns true if the given primitive is inside the focus trap
+// currently in effect or if there is no focus trap.
+func (a *Application) inFocusTrap(p Primitive) bool {
+	trap := a.GetFocusTrap()
+	return trap == nil || getPrimitivePath(trap, p) != nil
+}
+
+// notifyFocus invokes the blur callbacks of the primitives which contained the
+// previously focused primitive but don't contain the currently focused one,
+// from the inside out, and then the focus callbacks of the primitives which
+// now contain the focus but didn't before, from the outside in (see
+// Box.SetFocusFunc()).
+func notifyFocus(root, previous, current Primitive) {
+	previousPath, currentPath := getFocusPath(root, previous), getFocusPath(root, current)
+	contains := func(path []Primitive, p Primitive) bool {
+		for _, q := range path {
+			if q == p {
+				return true
+			}
+		}
+		return false
+	}
+
+	for index := len(previousPath) - 1; index >= 0; index-- {
+		p := previousPath[index]
+		if contains(currentPath, p) {
+			continue
+		}
+		if notifier, ok := p.(focusNotifier); ok {
+			if blur := notifier.GetBlurFunc(); blur != nil {
+				blur()
+			}
+		}
+	}
+
+	for _, p := range currentPath {
+		if contains(previousPath, p) {
+			continue
+		}
+		if notifier, ok := p.(focusNotifier); ok {
+			if focus := notifier.GetFocusFunc(); focus != nil {
+				focus()
+			}
+		}
+	}
+}
+
+// getFocusPath returns the path from the root primitive to the given focused
+// primitive. If the primitive is not part of the root's tree, the path only
+// contains the primitive itself. It is nil if the primitive is nil.
+func getFocusPath(root, p Primitive) []Primitive {
+	if p == nil {
+		return nil
+	}
+	if path := getPrimitivePath(root, p); path != nil {
+		return path
+	}
+	return []Primitive{p}
+}
+
+// getFocusCandidates returns the primitives in the given primitive's subtree
+// which may receive the focus through keyboard navigation, in the order in
+// which they appear in the tree. These are all primitives which do not contain
+// other primitives, which accept key events, which occupy space on the screen,
+// and whose tab index is not negative.
+func getFocusCandidates(p Primitive) []Primitive {
+	if p == nil {
+		return nil
+	}
+
+	if container, ok := p.(Container); ok {
+		if children := container.Children(); len(children) > 0 {
+			var candidates []Primitive
+			for _, child := range children {
+				candidates = append(candidates, getFocusCandidates(child)...)
+			}
+			return candidates
+		}
+	}
+
+	if _, _, width, height := p.GetRect(); width <= 0 || height <= 0 {
+		return nil
+	}
+	if p.InputHandler() == nil || getTabIndex(p) < 0 {
+		return nil
+	}
+	return []Primitive{p}
+}
+
+// getTabIndex returns the tab index of the given primitive (see
+// Box.SetTabIndex()).
+func getTabIndex(p Primitive) int {
+	if indexer, ok := p.(tabIndexer); ok {
+		return indexer.GetTabIndex()
+	}
+	return 0
+}
+
+// rangeGap returns the number of cells between the two given ranges or 0 if
+// they overlap.
+func rangeGap(start1, length1, start2, length2 int) int {
+	if start2 >= start1+length1 {
+		return start2 - (start1 + length1)
+	}
+	if start1 >= start2+length2 {
+		return start1 - (start2 + length2)
+	}
+	return 0
+}
@@ -0,0 +1,147 @@
+package tview
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/tcell"
+)
+
+// newFocusTestApp returns an application showing the given root primitive on
+// a simulation screen. The application is not running, key events are handed
+// to it with handleKey().
+func newFocusTestApp(root Primitive) *Application {
+	screen := tcell.NewSimulationScreen("")
+	screen.Init()
+	screen.SetSize(60, 5)
+	app := NewApplication()
+	app.SetScreen(screen)
+	app.SetRoot(root, true)
+	root.SetRect(0, 0, 60, 5)
+	root.Draw(screen)
+	return app
+}
+
+// newFocusTestField returns an input field labeled with the given name.
+func newFocusTestField(name string) *InputField {
+	return NewInputField().SetLabel(name)
+}
+
+// focusedLabel returns the label of the focused input field.
+func focusedLabel(app *Application) string {
+	if field, ok := app.GetFocus().(*InputField); ok {
+		return field.GetLabel()
+	}
+	return "none"
+}
+
+// pressKeys hands the given keys to the application and returns the labels of
+// the input fields focused after each key.
+func pressKeys(app *Application, keys ...tcell.Key) string {
+	var labels []string
+	for _, key := range keys {
+		app.handleKey(tcell.NewEventKey(key, 0, tcell.ModNone))
+		labels = append(labels, focusedLabel(app))
+	}
+	return strings.Join(labels, " ")
+}
+
+func TestFocusTabOrder(t *testing.T) {
+	a, b, c, d, e := newFocusTestField("a"), newFocusTestField("b"), newFocusTestField("c"), newFocusTestField("d"), newFocusTestField("e")
+	a.SetTabIndex(2)
+	c.SetTabIndex(1)
+	e.SetTabIndex(-1)
+	flex := NewFlex()
+	for _, field := range []*InputField{a, b, c, d, e} {
+		flex.AddItem(field, 0, 1, false)
+	}
+	app := newFocusTestApp(flex)
+
+	if order := pressKeys(app, tcell.KeyTab, tcell.KeyTab, tcell.KeyTab, tcell.KeyTab, tcell.KeyTab); order != "c a b d c" {
+		t.Errorf("Tab moved the focus to %s", order)
+	}
+	if order := pressKeys(app, tcell.KeyBacktab, tcell.KeyBacktab, tcell.KeyBacktab); order != "d b a" {
+		t.Errorf("Backtab moved the focus to %s", order)
+	}
+
+	// Primitives skipped by the tab order are left in it.
+	app.SetFocus(e)
+	if order := pressKeys(app, tcell.KeyTab); order != "c" {
+		t.Errorf("Tab moved the focus from e to %s", order)
+	}
+}
+
+func TestFocusTrap(t *testing.T) {
+	outside, x, y, z := newFocusTestField("outside"), newFocusTestField("x"), newFocusTestField("y"), newFocusTestField("z")
+	inner := NewFlex().AddItem(y, 0, 1, false).AddItem(z, 0, 1, false)
+	dialog := NewFlex().AddItem(x, 0, 1, false).AddItem(inner, 0, 2, false)
+	root := NewFlex().AddItem(outside, 0, 1, false).AddItem(dialog, 0, 3, false)
+	app := newFocusTestApp(root)
+	app.SetFocus(outside)
+
+	// The focus moves into the trap and stays there.
+	app.PushFocusTrap(dialog)
+	if app.GetFocusTrap() != dialog || focusedLabel(app) != "x" {
+		t.Fatalf("focus is on %s after pushing the trap", focusedLabel(app))
+	}
+	if order := pressKeys(app, tcell.KeyTab, tcell.KeyTab, tcell.KeyTab, tcell.KeyBacktab); order != "y z x z" {
+		t.Errorf("Tab moved the focus to %s inside the trap", order)
+	}
+
+	// Nested traps.
+	app.PushFocusTrap(inner)
+	if order := pressKeys(app, tcell.KeyTab, tcell.KeyTab); order != "y z" {
+		t.Errorf("Tab moved the focus to %s inside the nested trap", order)
+	}
+	app.PopFocusTrap()
+	if app.GetFocusTrap() != dialog || focusedLabel(app) != "z" {
+		t.Errorf("focus is on %s after popping the nested trap", focusedLabel(app))
+	}
+
+	// Releasing the trap restores the focus.
+	app.PopFocusTrap()
+	if app.GetFocusTrap() != nil || focusedLabel(app) != "outside" {
+		t.Errorf("focus is on %s after popping the trap", focusedLabel(app))
+	}
+	if order := pressKeys(app, tcell.KeyTab, tcell.KeyTab); order != "x y" {
+		t.Errorf("Tab moved the focus to %s after popping the trap", order)
+	}
+}
+
+func TestFocusCallbacks(t *testing.T) {
+	outside, x, y := newFocusTestField("outside"), newFocusTestField("x"), newFocusTestField("y")
+	dialog := NewFlex().AddItem(x, 0, 1, false).AddItem(y, 0, 1, false)
+	root := NewFlex().AddItem(outside, 0, 1, false).AddItem(dialog, 0, 2, false)
+	app := newFocusTestApp(root)
+
+	var calls []string
+	record := func(call string) func() {
+		return func() {
+			calls = append(calls, call)
+		}
+	}
+	for name, box := range map[string]*Box{"outside": outside.Box, "x": x.Box, "y": y.Box, "dialog": dialog.Box} {
+		box.SetFocusFunc(record("focus " + name)).SetBlurFunc(record("blur " + name))
+	}
+	app.SetFocusChangedFunc(func(previous, current Primitive) {
+		calls = append(calls, "changed")
+	})
+
+	tests := []struct {
+		focus Primitive
+		calls string
+	}{
+		{outside, "focus outside, changed"},
+		{x, "blur outside, focus dialog, focus x, changed"},
+		{y, "blur x, focus y, changed"},
+		{y, ""},
+		{outside, "blur y, blur dialog, focus outside, changed"},
+	}
+	for _, test := range tests {
+		calls = nil
+		app.SetFocus(test.focus)
+		if strings.Join(calls, ", ") != test.calls {
+			t.Errorf("focusing %s invoked %v, expected %s", focusedLabel(app), calls, test.calls)
+		}
+	}
+}
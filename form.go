@@ -515,6 +515,18 @@ func (f *Form) Draw(screen tcell.Screen) {
 	}
 }
 
+// Children returns the form items followed by the form's buttons.
+func (f *Form) Children() []Primitive {
+	children := make([]Primitive, 0, len(f.items)+len(f.buttons))
+	for _, item := range f.items {
+		children = append(children, item)
+	}
+	for _, button := range f.buttons {
+		children = append(children, button)
+	}
+	return children
+}
+
 // Focus is called by the application when the primitive receives focus.
 func (f *Form) Focus(delegate func(p Primitive)) {
 	if len(f.items)+len(f.buttons) == 0 {
@@ -556,7 +568,7 @@ func (f *Form) Focus(delegate func(p Primitive)) {
 	} else {
 		// We're selecting a button.
 		button := f.buttons[f.focusedElement-len(f.items)]
-		button.SetBlurFunc(handler)
+		button.SetExitFunc(handler)
 		delegate(button)
 	}
 }
@@ -578,3 +590,20 @@ func (f *Form) HasFocus() bool {
 	}
 	return false
 }
+
+// focusChild makes the given item or button the element which receives the
+// focus the next time the form is focused.
+func (f *Form) focusChild(child Primitive) {
+	for index, item := range f.items {
+		if item == child {
+			f.focusedElement = index
+			return
+		}
+	}
+	for index, button := range f.buttons {
+		if button == child {
+			f.focusedElement = len(f.items) + index
+			return
+		}
+	}
+}
@@ -142,6 +142,11 @@ func (f *Frame) Draw(screen tcell.Screen) {
 	f.primitive.Draw(screen)
 }
 
+// Children returns the primitive contained in the frame.
+func (f *Frame) Children() []Primitive {
+	return []Primitive{f.primitive}
+}
+
 // Focus is called when this primitive receives focus.
 func (f *Frame) Focus(delegate func(p Primitive)) {
 	delegate(f.primitive)
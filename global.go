@@ -1,12 +1,18 @@
 package tview
 
-import "github.com/diamondburned/tcell"
+import (
+	"time"
+
+	"github.com/diamondburned/tcell"
+)
 
 // application exposes the whole application as a singleton. This variable will
-// be filled when Newapplication() is called.
+// be filled when Initialize() is called. The functions in this file operate on
+// it. Applications created with NewApplication() are independent of it.
 var application *Application
 
-// Initialize creates a new application.
+// Initialize creates the global application, unless it already exists, and
+// returns it.
 func Initialize() *Application {
 	if application == nil {
 		application = NewApplication()
@@ -25,6 +31,10 @@ func Stop() {
 	application.Stop()
 }
 
+func Quit() {
+	application.Quit()
+}
+
 func Draw() {
 	application.Draw()
 }
@@ -33,18 +43,62 @@ func ForceDraw() {
 	application.ForceDraw()
 }
 
-func Suspend(f func() error) bool {
+func SetSynchronousDraw(enable bool) {
+	application.SetSynchronousDraw(enable)
+}
+
+func GetSynchronousDraw() bool {
+	return application.GetSynchronousDraw()
+}
+
+func WaitForFrame() {
+	application.WaitForFrame()
+}
+
+func Suspend(f func() error) error {
 	return application.Suspend(f)
 }
 
+func SuspendJob() {
+	application.SuspendJob()
+}
+
 func ExecApplication(f func(*Application) bool) {
 	application.ExecApplication(f)
 }
 
+func QueueUpdate(f func()) {
+	application.QueueUpdate(f)
+}
+
+func QueueUpdateDraw(f func()) {
+	application.QueueUpdateDraw(f)
+}
+
+func QueueUpdateWait(f func()) {
+	application.QueueUpdateWait(f)
+}
+
+func AfterFunc(d time.Duration, f func()) *Timer {
+	return application.AfterFunc(d, f)
+}
+
+func Every(d time.Duration, f func()) *Timer {
+	return application.Every(d, f)
+}
+
+func Animate(duration time.Duration, easing func(t float64) float64, f func(progress float64)) *Timer {
+	return application.Animate(duration, easing, f)
+}
+
 func QueueEvent(event tcell.Event) {
 	application.QueueEvent(event)
 }
 
+func QueueEventWait(event tcell.Event) {
+	application.QueueEventWait(event)
+}
+
 func SetInputCapture(capture func(event *tcell.EventKey) *tcell.EventKey) {
 	application.SetInputCapture(capture)
 }
@@ -53,6 +107,86 @@ func GetInputCapture() func(event *tcell.EventKey) *tcell.EventKey {
 	return application.GetInputCapture()
 }
 
+func SetBeforeQuitFunc(handler func() bool) {
+	application.SetBeforeQuitFunc(handler)
+}
+
+func GetBeforeQuitFunc() func() bool {
+	return application.GetBeforeQuitFunc()
+}
+
+func SetShutdownFunc(handler func()) {
+	application.SetShutdownFunc(handler)
+}
+
+func GetShutdownFunc() func() {
+	return application.GetShutdownFunc()
+}
+
+func SetKeymap(keymap *Keymap) {
+	application.SetKeymap(keymap)
+}
+
+func GetKeymap() *Keymap {
+	return application.GetKeymap()
+}
+
+func ActiveBindings() []Binding {
+	return application.ActiveBindings()
+}
+
+func SetTerminalFocusFunc(handler func(focused bool)) {
+	application.SetTerminalFocusFunc(handler)
+}
+
+func GetTerminalFocusFunc() func(focused bool) {
+	return application.GetTerminalFocusFunc()
+}
+
+func HasTerminalFocus() bool {
+	return application.HasTerminalFocus()
+}
+
+func SetTitle(title string) {
+	application.SetTitle(title)
+}
+
+func GetTitle() string {
+	return application.GetTitle()
+}
+
+func SetClipboard(text string) {
+	application.SetClipboard(text)
+}
+
+func GetClipboard() string {
+	return application.GetClipboard()
+}
+
+func Copy() {
+	application.Copy()
+}
+
+func Paste() {
+	application.Paste()
+}
+
+func SetClickToFocus(enable bool) {
+	application.SetClickToFocus(enable)
+}
+
+func GetClickToFocus() bool {
+	return application.GetClickToFocus()
+}
+
+func SetFocusFollowsMouse(enable bool) {
+	application.SetFocusFollowsMouse(enable)
+}
+
+func GetFocusFollowsMouse() bool {
+	return application.GetFocusFollowsMouse()
+}
+
 func SetScreen(screen tcell.Screen) {
 	application.SetScreen(screen)
 }
@@ -96,6 +230,50 @@ func GetFocus() Primitive {
 	return application.GetFocus()
 }
 
+func SetFocusChangedFunc(handler func(previous, current Primitive)) {
+	application.SetFocusChangedFunc(handler)
+}
+
+func GetFocusChangedFunc() func(previous, current Primitive) {
+	return application.GetFocusChangedFunc()
+}
+
+func FocusNext() {
+	application.FocusNext()
+}
+
+func FocusPrevious() {
+	application.FocusPrevious()
+}
+
+func FocusLeft() {
+	application.FocusLeft()
+}
+
+func FocusRight() {
+	application.FocusRight()
+}
+
+func FocusUp() {
+	application.FocusUp()
+}
+
+func FocusDown() {
+	application.FocusDown()
+}
+
+func PushFocusTrap(trap Primitive) {
+	application.PushFocusTrap(trap)
+}
+
+func PopFocusTrap() {
+	application.PopFocusTrap()
+}
+
+func GetFocusTrap() Primitive {
+	return application.GetFocusTrap()
+}
+
 func GetComponentAt(x, y int) *Primitive {
 	return application.GetComponentAt(x, y)
 }
@@ -242,6 +242,20 @@ func (g *Grid) GetOffset() (rows, columns int) {
 	return g.rowOffset, g.columnOffset
 }
 
+// Children returns the primitives which were visible the last time the grid
+// was drawn. Empty items are skipped.
+func (g *Grid) Children() []Primitive {
+	var children []Primitive
+	seen := make(map[Primitive]bool)
+	for _, item := range g.items {
+		if item.Item != nil && item.visible && !seen[item.Item] {
+			seen[item.Item] = true
+			children = append(children, item.Item)
+		}
+	}
+	return children
+}
+
 // Focus is called when this primitive receives focus.
 func (g *Grid) Focus(delegate func(p Primitive)) {
 	for _, item := range g.items {
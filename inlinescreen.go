@@ -0,0 +1,141 @@
+package tview
+
+import (
+	"os"
+
+	"github.com/diamondburned/tcell"
+)
+
+// The escape sequences which move the cursor up, down, and right by the given
+// number of cells, and which clear the terminal from the cursor to the end of
+// the line and to the end of the screen.
+const (
+	inlineUp         = "\x1b[%dA"
+	inlineDown       = "\x1b[%dB"
+	inlineRight      = "\x1b[%dC"
+	inlineClearLine  = "\x1b[K"
+	inlineClearBelow = "\x1b[J"
+)
+
+// NewInlineScreen returns a screen which renders into the process's terminal
+// without taking over the whole terminal, e.g. to use a Form, List, or DropDown
+// as an interactive prompt in a command line tool. The screen starts on the
+// cursor's line and occupies at least the given number of lines below it,
+// scrolling the terminal if needed. It grows when primitives are drawn further
+// down, e.g. the list of an open DropDown. When the application stops, the last
+// frame remains on the terminal and the cursor is placed below it:
+//
+//   screen, err := tview.NewInlineScreen(5)
+//   if err != nil {
+//       return err
+//   }
+//   if err := screen.Init(); err != nil {
+//       return err
+//   }
+//   app := tview.NewApplication()
+//   app.SetScreen(screen)
+//   app.SetRoot(form, true)
+//   return app.Run()
+//
+// A root primitive which fills the screen (see Application.SetRoot()) is given
+// the number of lines set with this function or SetInlineLines(). The terminal
+// type is taken from the TERM environment variable. Inline screens don't
+// support the mouse as terminals report mouse positions relative to the whole
+// terminal.
+//
+// An error is returned if the process has no terminal or if inline screens are
+// not supported on this platform (they are on Linux, macOS, and the BSDs).
+func NewInlineScreen(lines int) (*StreamScreen, error) {
+	tty, err := openTTY()
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := NewStreamScreen(tty, os.Getenv("TERM"))
+	if err != nil {
+		tty.Close()
+		return nil, err
+	}
+	s.tty = tty
+	s.inline = 1
+	if lines > 1 {
+		s.inline = lines
+	}
+
+	return s, nil
+}
+
+// SetInlineLines sets the minimum number of lines an inline screen occupies
+// (see NewInlineScreen()) and sends a resize event to the application so that
+// its root primitive is resized accordingly. The screen grows right away if
+// needed. It doesn't shrink until the application stops. Nothing happens if
+// the screen is not an inline screen.
+func (s *StreamScreen) SetInlineLines(lines int) {
+	s.Lock()
+	if s.inline == 0 {
+		s.Unlock()
+		return
+	}
+	if lines < 1 {
+		lines = 1
+	}
+	s.inline = lines
+	width, height := s.width, s.height
+	s.Unlock()
+
+	s.PostEvent(tcell.NewEventResize(width, height))
+}
+
+// GetInlineLines returns the minimum number of lines an inline screen
+// occupies or 0 if the screen uses the whole terminal.
+func (s *StreamScreen) GetInlineLines() int {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.inline
+}
+
+// grow makes an inline screen occupy the given number of lines (but not more
+// than the terminal has), scrolling the terminal if needed. The screen must be
+// locked.
+func (s *StreamScreen) grow(lines int) {
+	if lines > s.height {
+		lines = s.height
+	}
+	if lines <= s.lines {
+		return
+	}
+
+	s.puts(s.terminfo.AttrOff)
+	s.currentStyle = tcell.Style(-1)
+	if s.lines == 0 {
+		// Take over the cursor's line.
+		s.output.WriteString("\r" + inlineClearLine)
+		s.lines, s.line = 1, 0
+	}
+	s.moveTo(0, s.lines-1)
+	for ; s.lines < lines; s.lines++ {
+		s.output.WriteString("\r\n" + inlineClearLine)
+		s.line++
+	}
+	s.cursorX, s.cursorY = -1, -1
+}
+
+// usedLines returns the number of lines an inline screen needs to show all
+// cells which are not blank, i.e. which contain text or have a background
+// color. The screen must be locked.
+func (s *StreamScreen) usedLines() int {
+	for y := s.height - 1; y >= s.inline; y-- {
+		for x := 0; x < s.width; x++ {
+			mainc, _, style, _ := s.cells.GetContent(x, y)
+			if style == tcell.StyleDefault {
+				style = s.style
+			}
+			_, bg, attrs := style.Decompose()
+			if mainc != ' ' && mainc != 0 || bg != tcell.ColorDefault || attrs&(tcell.AttrReverse|tcell.AttrUnderline) != 0 {
+				return y + 1
+			}
+		}
+	}
+	return s.inline
+}
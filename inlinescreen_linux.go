@@ -0,0 +1,9 @@
+package tview
+
+import "syscall"
+
+// The requests which get and set the state of a terminal.
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
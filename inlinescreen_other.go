@@ -0,0 +1,29 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package tview
+
+import (
+	"errors"
+	"io"
+)
+
+// ttyStream is the process's terminal. Inline screens are not supported on
+// this platform.
+type ttyStream struct {
+	io.ReadWriteCloser
+}
+
+// openTTY returns an error as inline screens are not supported on this
+// platform.
+func openTTY() (*ttyStream, error) {
+	return nil, errors.New("inline screens are not supported on this platform")
+}
+
+// enter does nothing.
+func (t *ttyStream) enter(s *StreamScreen) error {
+	return nil
+}
+
+// leave does nothing.
+func (t *ttyStream) leave() {}
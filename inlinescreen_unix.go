@@ -0,0 +1,169 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package tview
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// ttyStream is the process's terminal, which inline screens read from and
+// write to.
+type ttyStream struct {
+	sync.Mutex
+
+	// The terminal, opened for reading and for writing.
+	in, out *os.File
+
+	// The terminal's state before it was switched to raw mode or nil if it
+	// is not in raw mode.
+	state *syscall.Termios
+
+	// Whether or not reading is paused because the screen was finalized, and
+	// the condition which signals that reading continues.
+	paused  bool
+	resumed *sync.Cond
+
+	// Receives window size changes.
+	winch chan os.Signal
+}
+
+// openTTY opens the process's terminal.
+func openTTY() (*ttyStream, error) {
+	in, err := os.OpenFile("/dev/tty", os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	out, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		in.Close()
+		return nil, err
+	}
+
+	t := &ttyStream{in: in, out: out}
+	t.resumed = sync.NewCond(&t.Mutex)
+	return t, nil
+}
+
+// Read reads input from the terminal. While the screen is finalized, input is
+// left to other readers, e.g. programs started with Application.Suspend().
+func (t *ttyStream) Read(p []byte) (int, error) {
+	for {
+		n, err := t.in.Read(p)
+		if n > 0 || !os.IsTimeout(err) {
+			return n, err
+		}
+
+		// Reading was interrupted by leave(). Wait for enter().
+		t.Lock()
+		for t.paused {
+			t.resumed.Wait()
+		}
+		t.Unlock()
+	}
+}
+
+// Write writes output to the terminal.
+func (t *ttyStream) Write(p []byte) (int, error) {
+	return t.out.Write(p)
+}
+
+// Close closes the terminal.
+func (t *ttyStream) Close() error {
+	t.in.Close()
+	return t.out.Close()
+}
+
+// enter switches the terminal to raw mode, updates the screen's size, and
+// starts watching the terminal's size. The screen must be locked.
+func (t *ttyStream) enter(s *StreamScreen) error {
+	fd := t.out.Fd()
+	state, err := getTermios(fd)
+	if err != nil {
+		return err
+	}
+
+	// The same settings as tcell's terminal screens.
+	raw := *state
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP |
+		syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Oflag &^= syscall.OPOST
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := setTermios(fd, &raw); err != nil {
+		return err
+	}
+	t.state = state
+
+	if width, height, err := getWinSize(fd); err == nil && width > 0 && height > 0 {
+		s.resize(width, height)
+	}
+	t.winch = make(chan os.Signal, 1)
+	signal.Notify(t.winch, syscall.SIGWINCH)
+	go func(winch chan os.Signal) {
+		for range winch {
+			if width, height, err := getWinSize(fd); err == nil && width > 0 && height > 0 {
+				s.SetSize(width, height)
+			}
+		}
+	}(t.winch)
+
+	t.Lock()
+	t.paused = false
+	t.in.SetReadDeadline(time.Time{})
+	t.resumed.Broadcast()
+	t.Unlock()
+
+	return nil
+}
+
+// leave stops reading input and watching the terminal's size, and restores
+// the terminal's state from before enter() was called.
+func (t *ttyStream) leave() {
+	signal.Stop(t.winch)
+	close(t.winch)
+
+	t.Lock()
+	t.paused = true
+	t.in.SetReadDeadline(time.Now())
+	t.Unlock()
+
+	if t.state != nil {
+		setTermios(t.out.Fd(), t.state)
+		t.state = nil
+	}
+}
+
+// getTermios returns the state of the terminal with the given file descriptor.
+func getTermios(fd uintptr) (*syscall.Termios, error) {
+	termios := &syscall.Termios{}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, ioctlGetTermios, uintptr(unsafe.Pointer(termios))); errno != 0 {
+		return nil, errno
+	}
+	return termios, nil
+}
+
+// setTermios sets the state of the terminal with the given file descriptor.
+func setTermios(fd uintptr, termios *syscall.Termios) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, ioctlSetTermios, uintptr(unsafe.Pointer(termios))); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// getWinSize returns the size of the terminal with the given file descriptor.
+func getWinSize(fd uintptr) (width, height int, err error) {
+	var size [4]uint16 // Rows, columns, and the size in pixels.
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&size))); errno != 0 {
+		return 0, 0, errno
+	}
+	return int(size[1]), int(size[0]), nil
+}
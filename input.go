@@ -3,7 +3,74 @@ package tview
 import "github.com/diamondburned/tcell"
 
 // MouseSupport is the interface which determines a primitive's
-// mouse capabilities
+// mouse capabilities. The handler receives the raw mouse events of the
+// terminal and returns true if the screen needs to be redrawn. Primitives
+// which want to receive mouse actions such as clicks and drags should
+// implement MouseEventSupport instead.
 type MouseSupport interface {
 	MouseHandler() func(*tcell.EventMouse) bool
 }
+
+// MouseEventSupport is the interface implemented by primitives which receive
+// mouse actions (see MouseEvent). The handler returns true if it consumed the
+// event. Mouse events which are not consumed bubble up to the containers of the
+// primitive (see Box.SetMouseCapture()).
+type MouseEventSupport interface {
+	MouseHandler() func(event *MouseEvent) bool
+}
+
+// KeyConsumer is an optional interface for primitives which, depending on
+// their state, use keys which may also be bound to global actions of the
+// application's keymap, e.g. Tab (see Keymap). While such a primitive has
+// focus, global bindings are not triggered by the keys it consumes and the keys
+// are forwarded to the primitive instead.
+type KeyConsumer interface {
+	ConsumesKey(event *tcell.EventKey) bool
+}
+
+// isTabKey returns whether or not the given key event is a Tab or a Backtab.
+func isTabKey(event *tcell.EventKey) bool {
+	return event.Key() == tcell.KeyTab || event.Key() == tcell.KeyBacktab
+}
+
+// PasteSupport is the interface implemented by primitives which accept pasted
+// text as a whole (see EventPaste). The handler receives the pasted text, with
+// line breaks normalized to "\n", when the primitive has focus.
+type PasteSupport interface {
+	PasteHandler() func(text string)
+}
+
+// CopySupport is the interface implemented by primitives which can copy their
+// content or their selection to the clipboard (see Application.Copy()). The
+// handler returns the text to be copied or an empty string if there is
+// nothing to copy.
+type CopySupport interface {
+	CopyHandler() func() string
+}
+
+// mouseCapturer is implemented by all primitives which embed Box. See
+// Box.SetMouseCapture().
+type mouseCapturer interface {
+	GetMouseCapture() func(event *MouseEvent) *MouseEvent
+}
+
+// focusNotifier is implemented by all primitives which embed Box. See
+// Box.SetFocusFunc() and Box.SetBlurFunc().
+type focusNotifier interface {
+	GetFocusFunc() func()
+	GetBlurFunc() func()
+}
+
+// tabIndexer is implemented by all primitives which embed Box. See
+// Box.SetTabIndex().
+type tabIndexer interface {
+	GetTabIndex() int
+}
+
+// focusDelegator is implemented by containers which hand their focus on to one
+// of their children (e.g. Form). If a descendant of such a container is
+// focused with the mouse, the container is told which child that descendant
+// belongs to and then receives the focus itself.
+type focusDelegator interface {
+	focusChild(child Primitive)
+}
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/diamondburned/tcell"
@@ -75,6 +76,10 @@ type InputField struct {
 	// The number of bytes of the text string skipped ahead while drawing.
 	offset int
 
+	// The horizontal screen position of the input area the last time the
+	// field was drawn.
+	fieldX int
+
 	// An optional autocomplete function which receives the current text of the
 	// input field and returns a slice of strings to be displayed in a drop-down
 	// selection.
@@ -357,6 +362,7 @@ func (i *InputField) Draw(screen tcell.Screen) {
 	if rightLimit-x < fieldWidth {
 		fieldWidth = rightLimit - x
 	}
+	i.fieldX = x
 
 	fieldStyle := tcell.StyleDefault
 	if i.invalid { // red background if invalid
@@ -464,6 +470,14 @@ func (i *InputField) Draw(screen tcell.Screen) {
 	}
 }
 
+// ConsumesKey returns whether or not the input field uses the given key itself.
+// This is the case for Tab and Backtab while autocomplete suggestions are
+// shown, if a handler was installed with SetDoneFunc(), or if the input field
+// is part of a form.
+func (i *InputField) ConsumesKey(event *tcell.EventKey) bool {
+	return isTabKey(event) && (i.autocompleteList != nil || i.done != nil || i.finished != nil)
+}
+
 // InputHandler returns the handler for this primitive.
 func (i *InputField) InputHandler() func(event *tcell.EventKey, setFocus func(p Primitive)) {
 	return i.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p Primitive)) {
@@ -630,3 +644,90 @@ func (i *InputField) InputHandler() func(event *tcell.EventKey, setFocus func(p
 		}
 	})
 }
+
+// MouseHandler returns the mouse handler for this primitive. Pressing the mouse
+// button over the input area moves the cursor to the character under the
+// pointer.
+func (i *InputField) MouseHandler() func(event *MouseEvent) bool {
+	return func(event *MouseEvent) bool {
+		x, _ := event.Position()
+		if event.Action != MouseDown || event.Button != tcell.Button1 || x < i.fieldX {
+			return false
+		}
+
+		// Find the character under the pointer, starting at the first visible
+		// one.
+		var maskWidth int
+		if i.maskCharacter > 0 {
+			maskWidth = runewidth.RuneWidth(i.maskCharacter)
+		}
+		offset := i.offset
+		if offset > len(i.text) {
+			offset = 0
+		}
+		i.CursorPos = len(i.text)
+		screenX := i.fieldX
+		iterateString(i.text[offset:], func(main rune, comb []rune, textPos, textWidth, screenPos, screenWidth int) bool {
+			if maskWidth > 0 {
+				screenWidth = maskWidth
+			}
+			if x < screenX+screenWidth {
+				i.CursorPos = offset + textPos
+				return true
+			}
+			screenX += screenWidth
+			return false
+		})
+
+		return true
+	}
+}
+
+// PasteHandler returns the handler for text pasted into this primitive. The
+// text is inserted at the cursor position as a whole, with line breaks and tabs
+// replaced by spaces and other control characters removed. The acceptance
+// function and the "changed" callback are called only once.
+func (i *InputField) PasteHandler() func(text string) {
+	return func(text string) {
+		text = strings.Map(func(r rune) rune {
+			switch {
+			case r == '\n' || r == '\t':
+				return ' '
+			case unicode.IsControl(r):
+				return -1
+			}
+			return r
+		}, strings.Replace(text, "\r\n", "\n", -1))
+		if text == "" {
+			return
+		}
+
+		i.autocompleteListMutex.Lock()
+		if i.CursorPos < 0 || i.CursorPos > len(i.text) {
+			i.CursorPos = len(i.text)
+		}
+		newText := i.text[:i.CursorPos] + text + i.text[i.CursorPos:]
+		lastChar, _ := utf8.DecodeLastRuneInString(text)
+		i.invalid = i.accept != nil && !i.accept(newText, lastChar)
+		i.text = newText
+		i.CursorPos += len(text)
+		i.autocompleteListMutex.Unlock()
+
+		i.Autocomplete()
+		if i.changed != nil {
+			i.changed(i.text)
+		}
+	}
+}
+
+// CopyHandler returns the handler which provides the text of this input field
+// for copying to the clipboard. Nothing is copied from masked input fields,
+// e.g. password fields.
+func (i *InputField) CopyHandler() func() string {
+	return func() string {
+		if i.maskCharacter != 0 {
+			return ""
+		}
+		return i.GetText()
+	}
+}
@@ -0,0 +1,61 @@
+package tview
+
+import "github.com/diamondburned/tcell"
+
+// SuspendAction is the name of the action in an application's default keymap
+// which calls Application.SuspendJob(). It is not bound to any keys by default.
+// To suspend the application with Ctrl-Z like other programs in a shell, bind
+// it:
+//
+//   app.GetKeymap().Bind(tview.SuspendAction, "Ctrl-Z")
+const SuspendAction = "suspend"
+
+// addJobControlActions adds the job control actions to the keymap.
+func (a *Application) addJobControlActions(keymap *Keymap) {
+	keymap.SetAction(SuspendAction, "Suspend the application", nil, a.SuspendJob)
+}
+
+// SuspendJob suspends the application (see Suspend()) and stops the process,
+// like Ctrl-Z does for other programs in a shell. The application resumes and
+// redraws the screen when the process is continued, e.g. with the shell's "fg"
+// command. The same happens when the process receives SIGTSTP while the
+// application is running.
+//
+// Nothing happens on platforms without job control or if the application
+// doesn't run on the process's terminal, i.e. on one of tcell's terminal
+// screens or on an inline screen (see NewInlineScreen()), e.g. when it is
+// served over a network (see StreamScreen).
+func (a *Application) SuspendJob() {
+	a.RLock()
+	screen := a.Screen
+	a.RUnlock()
+
+	if !jobControl || screen == nil || !controlsTerminal(screen) {
+		return
+	}
+
+	a.Suspend(a.stopProcess)
+}
+
+// syncScreen redraws the whole screen, e.g. after the process was continued
+// and the terminal content may have been changed.
+func (a *Application) syncScreen() {
+	a.RLock()
+	screen := a.Screen
+	suspended := a.suspended
+	a.RUnlock()
+
+	if screen != nil && !suspended {
+		screen.Sync()
+		a.Draw()
+	}
+}
+
+// controlsTerminal returns true if the given screen runs on the process's
+// terminal.
+func controlsTerminal(screen tcell.Screen) bool {
+	if inline, ok := screen.(*StreamScreen); ok {
+		return inline.tty != nil
+	}
+	return isTerminalScreen(screen)
+}
@@ -0,0 +1,18 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package tview
+
+// jobControl is true on platforms where processes can be stopped and continued
+// by the shell.
+const jobControl = false
+
+// watchJobControl does nothing on this platform.
+func (a *Application) watchJobControl() (stop func()) {
+	return func() {}
+}
+
+// stopProcess does nothing on this platform.
+func (a *Application) stopProcess() error {
+	return nil
+}
@@ -0,0 +1,77 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package tview
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// jobControl is true on platforms where processes can be stopped and continued
+// by the shell.
+const jobControl = true
+
+// watchJobControl suspends the application when the process receives SIGTSTP
+// and redraws the screen when it receives SIGCONT. It returns a function which
+// stops watching.
+func (a *Application) watchJobControl() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTSTP, syscall.SIGCONT)
+
+	// Holds a value while a suspension caused by SIGTSTP is queued or running.
+	suspending := make(chan struct{}, 1)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if sig != syscall.SIGTSTP {
+					a.QueueUpdate(a.syncScreen)
+					break
+				}
+
+				// Ignore the signal if the application is suspended already.
+				a.RLock()
+				suspended := a.suspended
+				a.RUnlock()
+				if suspended {
+					break
+				}
+				select {
+				case suspending <- struct{}{}:
+					a.QueueUpdate(func() {
+						a.SuspendJob()
+						<-suspending
+					})
+				default:
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// stopProcess stops the process group and returns when it was continued.
+// SIGSTOP is sent instead of SIGTSTP because the Go runtime keeps catching
+// SIGTSTP once it was passed to signal.Notify(), even after signal.Stop(), so
+// SIGTSTP would not stop the process.
+func (a *Application) stopProcess() error {
+	continued := make(chan os.Signal, 1)
+	signal.Notify(continued, syscall.SIGCONT)
+	defer signal.Stop(continued)
+
+	if err := syscall.Kill(0, syscall.SIGSTOP); err != nil {
+		return err
+	}
+	<-continued
+	return nil
+}
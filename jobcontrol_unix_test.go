@@ -0,0 +1,71 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package tview
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// The environment variable which makes TestStopProcess run as the child
+// process which is stopped.
+const stopProcessEnv = "TVIEW_TEST_STOP_PROCESS"
+
+func TestStopProcess(t *testing.T) {
+	if os.Getenv(stopProcessEnv) != "" {
+		// The child process: Watch for job control signals like a running
+		// application does, then stop.
+		app := NewApplication()
+		defer app.watchJobControl()()
+		if err := app.stopProcess(); err != nil {
+			fmt.Print(err)
+			os.Exit(1)
+		}
+		fmt.Print("continued")
+		return
+	}
+
+	// Run the child in its own process group so only the child is stopped.
+	var output bytes.Buffer
+	cmd := exec.Command(os.Args[0], "-test.run=^TestStopProcess$")
+	cmd.Env = append(os.Environ(), stopProcessEnv+"=1")
+	cmd.Stdout = &output
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Process.Kill()
+
+	// Wait for the child to stop.
+	stopped := make(chan syscall.WaitStatus, 1)
+	go func() {
+		var status syscall.WaitStatus
+		syscall.Wait4(cmd.Process.Pid, &status, syscall.WUNTRACED, nil)
+		stopped <- status
+	}()
+	select {
+	case status := <-stopped:
+		if !status.Stopped() {
+			t.Fatalf("child process did not stop but ended with status %v", status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("child process did not stop")
+	}
+
+	// Continue it.
+	if err := cmd.Process.Signal(syscall.SIGCONT); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("child process failed: %s, output: %q", err, output.String())
+	}
+	if !bytes.HasPrefix(output.Bytes(), []byte("continued")) {
+		t.Errorf("child process wrote %q after it was continued", output.String())
+	}
+}
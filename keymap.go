@@ -0,0 +1,623 @@
+package tview
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/diamondburned/tcell"
+)
+
+// Keymap maps keys to named actions. It is installed on an application with
+// Application.SetKeymap() and receives all key events after the application's
+// input capture function and before the primitive which has focus. Key events
+// which trigger an action (or which are part of a multi-key chord) are not
+// forwarded to that primitive.
+//
+// Keys are described by their names as used by tcell, separated by spaces for
+// chords, e.g. "q", "Ctrl-S", "Alt-Enter", "F1", "g g", or "Ctrl-X Ctrl-S".
+// Modifiers are written as "Ctrl-", "Alt-", "Shift-", and "Meta-" prefixes.
+// The space bar is called "Space".
+//
+// Each action has a scope: Either the whole application (a nil scope) or a
+// primitive. Actions scoped to a primitive are only active while the focus is
+// on that primitive or one of its descendants. If bindings of different scopes
+// match a key, the binding of the innermost scope wins. Within the same scope,
+// Bind() refuses bindings which are equal to or a prefix of another binding.
+// Global bindings are not triggered by keys which the focused primitive
+// consumes (see KeyConsumer), e.g. Tab in a List.
+type Keymap struct {
+	sync.Mutex
+
+	// The actions by name.
+	actions map[string]*keyAction
+
+	// The names of the actions in the order they were added.
+	order []string
+
+	// All bindings in the order they were made.
+	bindings []*keyBinding
+
+	// The keys of a chord entered so far.
+	pending []keyStroke
+}
+
+// keyAction is a named action.
+type keyAction struct {
+	name, description string
+	scope             Primitive
+	handler           func()
+}
+
+// keyBinding binds a key sequence to an action.
+type keyBinding struct {
+	keys   []keyStroke
+	action *keyAction
+}
+
+// Binding describes a key binding. It is returned by Keymap.Bindings().
+type Binding struct {
+	// The keys, e.g. "Ctrl-X Ctrl-S".
+	Keys string
+
+	// The name and the description of the action triggered by the keys.
+	Action, Description string
+
+	// The primitive whose subtree the binding is active in, or nil if it is
+	// active in the whole application.
+	Scope Primitive
+}
+
+// NewKeymap returns a new, empty keymap.
+func NewKeymap() *Keymap {
+	return &Keymap{
+		actions: make(map[string]*keyAction),
+	}
+}
+
+// SetAction adds an action with the given name or, if it already exists,
+// replaces its description, scope, and handler while keeping its bindings. The
+// description is meant for help screens. The handler is called on the event
+// loop when one of the action's bindings was entered. "scope" is the primitive
+// the action is restricted to (see Keymap) or nil for the whole application.
+func (k *Keymap) SetAction(name, description string, scope Primitive, handler func()) *Keymap {
+	k.Lock()
+	defer k.Unlock()
+
+	if action, ok := k.actions[name]; ok {
+		action.description, action.scope, action.handler = description, scope, handler
+		return k
+	}
+	k.actions[name] = &keyAction{
+		name:        name,
+		description: description,
+		scope:       scope,
+		handler:     handler,
+	}
+	k.order = append(k.order, name)
+	return k
+}
+
+// RemoveAction removes the action with the given name and all its bindings.
+func (k *Keymap) RemoveAction(name string) *Keymap {
+	k.Lock()
+	defer k.Unlock()
+
+	action, ok := k.actions[name]
+	if !ok {
+		return k
+	}
+	delete(k.actions, name)
+	for index, n := range k.order {
+		if n == name {
+			k.order = append(k.order[:index], k.order[index+1:]...)
+			break
+		}
+	}
+	k.removeBindings(action, nil)
+	return k
+}
+
+// HasAction returns true if an action with the given name exists.
+func (k *Keymap) HasAction(name string) bool {
+	k.Lock()
+	defer k.Unlock()
+
+	_, ok := k.actions[name]
+	return ok
+}
+
+// Bind binds the given keys to the action with the given name. Each string in
+// "keys" is one binding, possibly a chord. An error is returned if the action
+// doesn't exist, if the keys can't be parsed, or if a binding conflicts with
+// an existing binding of the same scope, i.e. if one is equal to or a prefix of
+// the other. In case of an error, no bindings are made.
+func (k *Keymap) Bind(action string, keys ...string) error {
+	k.Lock()
+	defer k.Unlock()
+
+	a, ok := k.actions[action]
+	if !ok {
+		return fmt.Errorf("unknown action %q", action)
+	}
+
+	bindings := make([]*keyBinding, 0, len(keys))
+	for _, str := range keys {
+		strokes, err := parseKeyStrokes(str)
+		if err != nil {
+			return err
+		}
+		bindings = append(bindings, &keyBinding{keys: strokes, action: a})
+	}
+
+	existing := k.bindings
+	for _, binding := range bindings {
+		if err := checkBinding(binding, existing); err != nil {
+			return err
+		}
+		existing = append(existing, binding)
+	}
+	k.bindings = existing
+
+	return nil
+}
+
+// Unbind removes the given bindings from the action with the given name. If no
+// keys are given, all bindings of the action are removed.
+func (k *Keymap) Unbind(action string, keys ...string) error {
+	k.Lock()
+	defer k.Unlock()
+
+	a, ok := k.actions[action]
+	if !ok {
+		return fmt.Errorf("unknown action %q", action)
+	}
+
+	var sequences [][]keyStroke
+	for _, str := range keys {
+		strokes, err := parseKeyStrokes(str)
+		if err != nil {
+			return err
+		}
+		sequences = append(sequences, strokes)
+	}
+	if len(sequences) == 0 {
+		sequences = nil
+	}
+	k.removeBindings(a, sequences)
+
+	return nil
+}
+
+// Bindings returns all bindings, grouped by action in the order the actions
+// were added. Actions without bindings are included with empty keys so that
+// help screens can list them.
+func (k *Keymap) Bindings() []Binding {
+	k.Lock()
+	defer k.Unlock()
+
+	var bindings []Binding
+	for _, name := range k.order {
+		action := k.actions[name]
+		var found bool
+		for _, binding := range k.bindings {
+			if binding.action == action {
+				bindings = append(bindings, action.binding(binding.keys))
+				found = true
+			}
+		}
+		if !found {
+			bindings = append(bindings, action.binding(nil))
+		}
+	}
+
+	return bindings
+}
+
+// ActiveBindings returns the bindings which can currently be triggered, given
+// the path from the root primitive to the primitive which has focus. Bindings
+// which are shadowed by bindings of an inner scope are omitted.
+func (k *Keymap) ActiveBindings(path []Primitive) []Binding {
+	k.Lock()
+	defer k.Unlock()
+
+	var bindings []Binding
+	for _, binding := range k.bindings {
+		depth := scopeDepth(binding.action.scope, path)
+		if depth < -1 {
+			continue // Not active.
+		}
+
+		// Is it shadowed?
+		var shadowed bool
+		for _, other := range k.bindings {
+			if other == binding || !overlaps(other.keys, binding.keys) {
+				continue
+			}
+			if scopeDepth(other.action.scope, path) > depth {
+				shadowed = true
+				break
+			}
+		}
+		if !shadowed {
+			bindings = append(bindings, binding.action.binding(binding.keys))
+		}
+	}
+
+	return bindings
+}
+
+// LoadBindings reads bindings from a JSON object which maps action names to
+// lists of keys, e.g.:
+//
+//   {
+//     "save": ["Ctrl-S", "Ctrl-X Ctrl-S"],
+//     "quit": ["q", "Ctrl-Q"]
+//   }
+//
+// The bindings of each listed action are replaced. An empty list removes all
+// of the action's bindings. Actions not listed keep their bindings. An error
+// is returned if the JSON is invalid, if an action doesn't exist, or if
+// bindings conflict (see Bind()). In case of an error, no bindings are changed.
+func (k *Keymap) LoadBindings(r io.Reader) error {
+	var config map[string][]string
+	if err := json.NewDecoder(r).Decode(&config); err != nil {
+		return err
+	}
+
+	k.Lock()
+	defer k.Unlock()
+
+	// Remove the bindings of the listed actions.
+	var bindings []*keyBinding
+	for _, binding := range k.bindings {
+		if _, ok := config[binding.action.name]; !ok {
+			bindings = append(bindings, binding)
+		}
+	}
+
+	// Add the new bindings, in a deterministic order.
+	for _, name := range k.order {
+		keys, ok := config[name]
+		if !ok {
+			continue
+		}
+		delete(config, name)
+		for _, str := range keys {
+			strokes, err := parseKeyStrokes(str)
+			if err != nil {
+				return err
+			}
+			binding := &keyBinding{keys: strokes, action: k.actions[name]}
+			if err := checkBinding(binding, bindings); err != nil {
+				return err
+			}
+			bindings = append(bindings, binding)
+		}
+	}
+	for name := range config {
+		return fmt.Errorf("unknown action %q", name)
+	}
+
+	k.bindings = bindings
+	k.pending = nil
+
+	return nil
+}
+
+// Trigger calls the handler of the action with the given name. Returns false
+// if there is no such action.
+func (k *Keymap) Trigger(name string) bool {
+	k.Lock()
+	action, ok := k.actions[name]
+	var handler func()
+	if ok {
+		handler = action.handler
+	}
+	k.Unlock()
+
+	if !ok {
+		return false
+	}
+	if handler != nil {
+		handler()
+	}
+	return true
+}
+
+// handle processes a key event, given the path from the root primitive to the
+// primitive which has focus. Returns true if the event was consumed, either
+// because it triggered an action or because it is part of a chord.
+func (k *Keymap) handle(event *tcell.EventKey, path []Primitive) bool {
+	k.Lock()
+
+	// Keys consumed by the focused primitive don't trigger global bindings,
+	// unless they continue a chord.
+	var consumed bool
+	if len(path) > 0 {
+		if consumer, ok := path[len(path)-1].(KeyConsumer); ok {
+			consumed = consumer.ConsumesKey(event)
+		}
+	}
+
+	stroke := newKeyStroke(event.Key(), event.Rune(), event.Modifiers())
+	sequence := append(k.pending, stroke)
+	action, wait := k.resolve(sequence, path, consumed && len(sequence) == 1)
+	if action == nil && !wait && len(k.pending) > 0 {
+		// The chord was broken. Try the key on its own.
+		sequence = []keyStroke{stroke}
+		action, wait = k.resolve(sequence, path, consumed)
+	}
+
+	k.pending = nil
+	if wait {
+		k.pending = sequence
+	}
+	var handler func()
+	if action != nil {
+		handler = action.handler
+	}
+	k.Unlock()
+
+	if action != nil {
+		if handler != nil {
+			handler()
+		}
+		return true
+	}
+	return wait
+}
+
+// resolve finds the action bound to the given key sequence. If the sequence is
+// the beginning of a chord, "wait" is true. If "scopedOnly" is true, global
+// bindings are ignored.
+func (k *Keymap) resolve(sequence []keyStroke, path []Primitive, scopedOnly bool) (action *keyAction, wait bool) {
+	best := -2 // The depth of the innermost matching scope.
+	for _, binding := range k.bindings {
+		if len(binding.keys) < len(sequence) || !equalStrokes(binding.keys[:len(sequence)], sequence) {
+			continue
+		}
+		if scopedOnly && binding.action.scope == nil {
+			continue
+		}
+		depth := scopeDepth(binding.action.scope, path)
+		if depth <= best {
+			continue // Not active or shadowed by a better match.
+		}
+		best = depth
+		if len(binding.keys) == len(sequence) {
+			action, wait = binding.action, false
+		} else {
+			action, wait = nil, true
+		}
+	}
+
+	return
+}
+
+// removeBindings removes the action's bindings for the given key sequences, or
+// all of them if "sequences" is nil.
+func (k *Keymap) removeBindings(action *keyAction, sequences [][]keyStroke) {
+	bindings := k.bindings[:0]
+	for _, binding := range k.bindings {
+		remove := binding.action == action
+		if remove && sequences != nil {
+			remove = false
+			for _, sequence := range sequences {
+				if equalStrokes(binding.keys, sequence) {
+					remove = true
+					break
+				}
+			}
+		}
+		if !remove {
+			bindings = append(bindings, binding)
+		}
+	}
+	k.bindings = bindings
+	k.pending = nil
+}
+
+// binding returns the exported description of the given keys bound to this
+// action.
+func (a *keyAction) binding(keys []keyStroke) Binding {
+	return Binding{
+		Keys:        formatKeyStrokes(keys),
+		Action:      a.name,
+		Description: a.description,
+		Scope:       a.scope,
+	}
+}
+
+// checkBinding returns an error if the binding conflicts with one of the given
+// bindings of the same scope. Binding the same keys to the same action twice is
+// not an error.
+func checkBinding(binding *keyBinding, bindings []*keyBinding) error {
+	for _, other := range bindings {
+		if other.action.scope != binding.action.scope || !overlaps(other.keys, binding.keys) {
+			continue
+		}
+		if other.action == binding.action && equalStrokes(other.keys, binding.keys) {
+			continue
+		}
+		return fmt.Errorf("keys %q of action %q conflict with keys %q of action %q",
+			formatKeyStrokes(binding.keys), binding.action.name,
+			formatKeyStrokes(other.keys), other.action.name)
+	}
+	return nil
+}
+
+// scopeDepth returns the index of the scope in the given path, -1 for the
+// application scope (nil), or -2 if the scope is not on the path.
+func scopeDepth(scope Primitive, path []Primitive) int {
+	if scope == nil {
+		return -1
+	}
+	for index, p := range path {
+		if p == scope {
+			return index
+		}
+	}
+	return -2
+}
+
+// keyStroke is a single key, normalized so that key events and parsed key
+// names can be compared.
+type keyStroke struct {
+	key tcell.Key
+	ch  rune
+	mod tcell.ModMask
+}
+
+// newKeyStroke returns a normalized key stroke. The Shift modifier is dropped
+// from runes as it is already reflected in the rune, and the Ctrl modifier is
+// dropped from control keys as it is part of the key.
+func newKeyStroke(key tcell.Key, ch rune, mod tcell.ModMask) keyStroke {
+	if key == tcell.KeyRune {
+		return keyStroke{key: key, ch: ch, mod: mod &^ tcell.ModShift}
+	}
+	if key <= tcell.KeyUS || key == tcell.KeyDEL {
+		mod &^= tcell.ModCtrl
+	}
+	return keyStroke{key: key, mod: mod}
+}
+
+// String returns the name of the key stroke.
+func (s keyStroke) String() string {
+	var name string
+	if s.mod&tcell.ModCtrl != 0 {
+		name += "Ctrl-"
+	}
+	if s.mod&tcell.ModAlt != 0 {
+		name += "Alt-"
+	}
+	if s.mod&tcell.ModShift != 0 {
+		name += "Shift-"
+	}
+	if s.mod&tcell.ModMeta != 0 {
+		name += "Meta-"
+	}
+	switch {
+	case s.key == tcell.KeyRune && s.ch == ' ':
+		name += "Space"
+	case s.key == tcell.KeyRune:
+		name += string(s.ch)
+	case tcell.KeyNames[s.key] != "":
+		name += tcell.KeyNames[s.key]
+	default:
+		name += fmt.Sprintf("Key%d", s.key)
+	}
+	return name
+}
+
+// keyNames maps lowercase key names to keys.
+var keyNames = func() map[string]tcell.Key {
+	names := make(map[string]tcell.Key)
+	for key, name := range tcell.KeyNames {
+		names[strings.ToLower(name)] = key
+	}
+	return names
+}()
+
+// keyModifiers maps modifier prefixes to modifiers.
+var keyModifiers = []struct {
+	prefix string
+	mod    tcell.ModMask
+}{
+	{"ctrl-", tcell.ModCtrl},
+	{"alt-", tcell.ModAlt},
+	{"shift-", tcell.ModShift},
+	{"meta-", tcell.ModMeta},
+}
+
+// parseKeyStroke parses the name of a single key, e.g. "Ctrl-S" or "g".
+func parseKeyStroke(str string) (keyStroke, error) {
+	name := str
+	var mod tcell.ModMask
+	for {
+		if key, ok := keyNames[strings.ToLower(name)]; ok {
+			return newKeyStroke(key, 0, mod), nil
+		}
+
+		// Strip a modifier.
+		var found bool
+		for _, modifier := range keyModifiers {
+			if len(name) > len(modifier.prefix) && strings.HasPrefix(strings.ToLower(name), modifier.prefix) {
+				name = name[len(modifier.prefix):]
+				mod |= modifier.mod
+				found = true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+	}
+
+	if strings.ToLower(name) == "space" {
+		name = " "
+	}
+	if r, size := utf8.DecodeRuneInString(name); size == len(name) && r != utf8.RuneError {
+		if mod&tcell.ModCtrl != 0 {
+			// Control keys have their own key codes.
+			if key, ok := keyNames["ctrl-"+strings.ToLower(name)]; ok {
+				return newKeyStroke(key, 0, mod), nil
+			}
+		}
+		return newKeyStroke(tcell.KeyRune, r, mod), nil
+	}
+
+	return keyStroke{}, fmt.Errorf("invalid key %q", str)
+}
+
+// parseKeyStrokes parses a space-separated sequence of key names.
+func parseKeyStrokes(str string) ([]keyStroke, error) {
+	fields := strings.Fields(str)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no keys in %q", str)
+	}
+	strokes := make([]keyStroke, len(fields))
+	for index, field := range fields {
+		stroke, err := parseKeyStroke(field)
+		if err != nil {
+			return nil, err
+		}
+		strokes[index] = stroke
+	}
+	return strokes, nil
+}
+
+// formatKeyStrokes returns the names of the given key strokes, separated by
+// spaces.
+func formatKeyStrokes(strokes []keyStroke) string {
+	names := make([]string, len(strokes))
+	for index, stroke := range strokes {
+		names[index] = stroke.String()
+	}
+	return strings.Join(names, " ")
+}
+
+// equalStrokes returns true if the two key sequences are equal.
+func equalStrokes(a, b []keyStroke) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for index := range a {
+		if a[index] != b[index] {
+			return false
+		}
+	}
+	return true
+}
+
+// overlaps returns true if one key sequence is equal to or a prefix of the
+// other.
+func overlaps(a, b []keyStroke) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return equalStrokes(a, b[:len(a)])
+}
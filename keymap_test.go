@@ -0,0 +1,286 @@
+package tview
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/tcell"
+)
+
+func TestParseKeyStroke(t *testing.T) {
+	tests := []struct {
+		name, expected string
+	}{
+		{"q", "q"},
+		{"Q", "Q"},
+		{"Shift-q", "q"},
+		{"Space", "Space"},
+		{"Alt-space", "Alt-Space"},
+		{"Ctrl-S", "Ctrl-S"},
+		{"ctrl-s", "Ctrl-S"},
+		{"Ctrl-Alt-x", "Alt-Ctrl-X"},
+		{"Alt-Enter", "Alt-Enter"},
+		{"Shift-Tab", "Shift-Tab"},
+		{"Backtab", "Backtab"},
+		{"F1", "F1"},
+		{"Ctrl-Up", "Ctrl-Up"},
+		{"Meta-ä", "Meta-ä"},
+		{"-", "-"},
+		{"Ctrl--", "Ctrl--"},
+	}
+	for _, test := range tests {
+		stroke, err := parseKeyStroke(test.name)
+		if err != nil {
+			t.Errorf("parsing %q failed: %s", test.name, err)
+			continue
+		}
+		if name := stroke.String(); name != test.expected {
+			t.Errorf("%q is named %q, expected %q", test.name, name, test.expected)
+			continue
+		}
+
+		// The name parses to the same key stroke.
+		if again, err := parseKeyStroke(stroke.String()); err != nil || again != stroke {
+			t.Errorf("%q doesn't round-trip: %v, %v", test.name, again, err)
+		}
+	}
+
+	for _, name := range []string{"", "Ctrl-", "ab", "Hyper-x", "F99"} {
+		if _, err := parseKeyStroke(name); err == nil {
+			t.Errorf("invalid key %q was parsed", name)
+		}
+	}
+
+	// Key events map to the same key strokes as their names.
+	events := map[string]*tcell.EventKey{
+		"Ctrl-S":    tcell.NewEventKey(tcell.KeyCtrlS, 0, tcell.ModCtrl),
+		"A":         tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModShift),
+		"Alt-Enter": tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModAlt),
+	}
+	for name, event := range events {
+		stroke, _ := parseKeyStroke(name)
+		if eventStroke := newKeyStroke(event.Key(), event.Rune(), event.Modifiers()); eventStroke != stroke {
+			t.Errorf("event %s is %v, expected %v", event.Name(), eventStroke, stroke)
+		}
+	}
+}
+
+// testKeymap returns a keymap with the given global actions which record
+// their calls.
+func testKeymap(calls *[]string, actions ...string) *Keymap {
+	keymap := NewKeymap()
+	for _, name := range actions {
+		keymap.SetAction(name, "", nil, recordCall(calls, name))
+	}
+	return keymap
+}
+
+// recordCall returns a handler which appends the given name to the calls.
+func recordCall(calls *[]string, name string) func() {
+	return func() {
+		*calls = append(*calls, name)
+	}
+}
+
+// typeKeys sends the given key names to the keymap and returns the names of
+// the keys which were not consumed.
+func typeKeys(keymap *Keymap, path []Primitive, keys string) (unconsumed []string) {
+	for _, name := range strings.Fields(keys) {
+		stroke, err := parseKeyStroke(name)
+		if err != nil {
+			panic(err)
+		}
+		event := tcell.NewEventKey(stroke.key, stroke.ch, stroke.mod)
+		if !keymap.handle(event, path) {
+			unconsumed = append(unconsumed, name)
+		}
+	}
+	return
+}
+
+func TestKeymapBind(t *testing.T) {
+	var calls []string
+	keymap := testKeymap(&calls, "a", "b")
+	scoped := NewBox()
+	keymap.SetAction("c", "", scoped, nil)
+
+	if err := keymap.Bind("a", "g g", "Ctrl-S"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		action, keys string
+		conflict     bool
+	}{
+		{"b", "g", true},         // Prefix of a chord.
+		{"b", "g g x", true},     // Extends a chord.
+		{"b", "ctrl-s", true},    // Same key.
+		{"a", "Ctrl-S", false},   // Same binding again.
+		{"b", "g h", false},      // Different chord.
+		{"c", "g", false},        // Different scope.
+		{"b", "x Ctrl-S", false}, // Overlaps only later in the sequence.
+	}
+	for _, test := range tests {
+		err := keymap.Bind(test.action, test.keys)
+		if test.conflict && err == nil {
+			t.Errorf("binding %q to %q was not refused", test.keys, test.action)
+		} else if !test.conflict && err != nil {
+			t.Errorf("binding %q to %q failed: %s", test.keys, test.action, err)
+		}
+	}
+
+	// A conflict in one of several keys makes no bindings.
+	before := len(keymap.Bindings())
+	if err := keymap.Bind("b", "y", "g"); err == nil {
+		t.Error("conflicting keys were bound")
+	}
+	if err := keymap.Bind("b", "z", "Hyper-z"); err == nil {
+		t.Error("invalid keys were bound")
+	}
+	if err := keymap.Bind("d", "z"); err == nil {
+		t.Error("keys were bound to an unknown action")
+	}
+	if after := len(keymap.Bindings()); after != before {
+		t.Errorf("%d bindings after errors, expected %d", after, before)
+	}
+}
+
+func TestKeymapChords(t *testing.T) {
+	var calls []string
+	keymap := testKeymap(&calls, "top", "save", "x")
+	keymap.Bind("top", "g g")
+	keymap.Bind("save", "Ctrl-X Ctrl-S")
+	keymap.Bind("x", "x")
+
+	tests := []struct {
+		keys, calls, unconsumed string
+	}{
+		{"g g", "top", ""},
+		{"Ctrl-X Ctrl-S", "save", ""},
+		{"g x", "x", ""},          // The broken chord falls back to the single key.
+		{"g y", "", "y"},          // Unbound keys are forwarded.
+		{"Ctrl-X g g", "top", ""}, // The second key starts a new chord.
+		{"g", "", ""},             // Waiting for the rest of the chord.
+		{"g", "top", ""},
+	}
+	for _, test := range tests {
+		calls = nil
+		unconsumed := typeKeys(keymap, nil, test.keys)
+		if strings.Join(calls, " ") != test.calls || strings.Join(unconsumed, " ") != test.unconsumed {
+			t.Errorf("%q called %v and didn't consume %v, expected %q and %q", test.keys, calls, unconsumed, test.calls, test.unconsumed)
+		}
+	}
+}
+
+// keyConsumer is a primitive which consumes Tab.
+type keyConsumer struct {
+	*Box
+}
+
+func (c *keyConsumer) ConsumesKey(event *tcell.EventKey) bool {
+	return event.Key() == tcell.KeyTab
+}
+
+func TestKeymapScopes(t *testing.T) {
+	var calls []string
+	keymap := testKeymap(&calls, "global", "next")
+	outer, inner := NewBox(), &keyConsumer{Box: NewBox()}
+	keymap.SetAction("outer", "", outer, recordCall(&calls, "outer"))
+	keymap.SetAction("inner", "", inner, recordCall(&calls, "inner"))
+	keymap.Bind("global", "q", "g g")
+	keymap.Bind("next", "Tab")
+	keymap.Bind("outer", "q", "o")
+	keymap.Bind("inner", "g", "i")
+
+	tests := []struct {
+		path                    []Primitive
+		keys, calls, unconsumed string
+	}{
+		{nil, "q g g Tab o i", "global global next", "o i"},
+		{[]Primitive{outer}, "q g g o i", "outer global outer", "i"},
+		{[]Primitive{outer, inner}, "q g o i", "outer inner outer inner", ""},
+
+		// The inner scope consumes Tab.
+		{[]Primitive{outer}, "Tab", "next", ""},
+		{[]Primitive{outer, inner}, "Tab", "", "Tab"},
+	}
+	for _, test := range tests {
+		calls = nil
+		unconsumed := typeKeys(keymap, test.path, test.keys)
+		if strings.Join(calls, " ") != test.calls || strings.Join(unconsumed, " ") != test.unconsumed {
+			t.Errorf("%q in %d scopes called %v and didn't consume %v, expected %q and %q", test.keys, len(test.path), calls, unconsumed, test.calls, test.unconsumed)
+		}
+	}
+
+	// Shadowed bindings are not active.
+	var active []string
+	for _, binding := range keymap.ActiveBindings([]Primitive{outer, inner}) {
+		active = append(active, binding.Action+":"+binding.Keys)
+	}
+	if strings.Join(active, " ") != "next:Tab outer:q outer:o inner:g inner:i" {
+		t.Errorf("active bindings are %v", active)
+	}
+}
+
+func TestKeymapLoadBindings(t *testing.T) {
+	var calls []string
+	keymap := testKeymap(&calls, "save", "quit")
+	keymap.Bind("save", "Ctrl-S")
+	keymap.Bind("quit", "q")
+
+	// describe returns the keymap's bindings.
+	describe := func() string {
+		var bindings []string
+		for _, binding := range keymap.Bindings() {
+			bindings = append(bindings, binding.Action+":"+binding.Keys)
+		}
+		return strings.Join(bindings, " ")
+	}
+
+	for _, config := range []string{
+		`{"save": ["Ctrl-X Ctrl-S"], "quit": ["Ctrl-X"]}`, // Conflict.
+		`{"save": ["Hyper-S"]}`,                           // Invalid key.
+		`{"save": ["Ctrl-S"], "open": ["Ctrl-O"]}`,        // Unknown action.
+		`{"save": "Ctrl-S"}`,                              // Invalid JSON.
+	} {
+		if err := keymap.LoadBindings(strings.NewReader(config)); err == nil {
+			t.Errorf("loading %s didn't fail", config)
+		}
+		if bindings := describe(); bindings != "save:Ctrl-S quit:q" {
+			t.Errorf("loading %s changed the bindings to %s", config, bindings)
+		}
+	}
+
+	if err := keymap.LoadBindings(strings.NewReader(`{"save": ["Ctrl-X Ctrl-S", "F2"]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if bindings := describe(); bindings != "save:Ctrl-X Ctrl-S save:F2 quit:q" {
+		t.Errorf("bindings are %s after loading", bindings)
+	}
+	if err := keymap.LoadBindings(strings.NewReader(`{"quit": []}`)); err != nil {
+		t.Fatal(err)
+	}
+	if bindings := describe(); bindings != "save:Ctrl-X Ctrl-S save:F2 quit:" {
+		t.Errorf("bindings are %s after removing bindings", bindings)
+	}
+}
+
+func TestKeymapTrigger(t *testing.T) {
+	var calls []string
+	keymap := testKeymap(&calls, "save")
+	if !keymap.Trigger("save") || keymap.Trigger("open") {
+		t.Error("unexpected results of Trigger()")
+	}
+
+	// Replacing the handler while it is triggered doesn't race.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for n := 0; n < 100; n++ {
+			keymap.SetAction("save", "", nil, func() {})
+		}
+	}()
+	for n := 0; n < 100; n++ {
+		keymap.Trigger("save")
+	}
+	<-done
+}
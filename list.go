@@ -486,6 +486,12 @@ func (l *List) Draw(screen tcell.Screen) {
 	}
 }
 
+// ConsumesKey returns whether or not the list uses the given key itself. This
+// is the case for Tab and Backtab, which move between the list items.
+func (l *List) ConsumesKey(event *tcell.EventKey) bool {
+	return isTabKey(event)
+}
+
 // InputHandler returns the handler for this primitive.
 func (l *List) InputHandler() func(event *tcell.EventKey, setFocus func(p Primitive)) {
 	return l.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p Primitive)) {
@@ -556,3 +562,72 @@ func (l *List) InputHandler() func(event *tcell.EventKey, setFocus func(p Primit
 		}
 	})
 }
+
+// indexAtPoint returns the index of the list item shown at the given screen
+// coordinates or -1 if there is no item at that position.
+func (l *List) indexAtPoint(x, y int) int {
+	if !l.InInnerRect(x, y) {
+		return -1
+	}
+
+	_, rectY, _, _ := l.GetInnerRect()
+	index := y - rectY
+	if l.showSecondaryText {
+		index /= 2
+	}
+	index += l.offset
+
+	if index >= len(l.Items) {
+		return -1
+	}
+	return index
+}
+
+// MouseHandler returns the mouse handler for this primitive. Clicking an item
+// makes it the current item, double-clicking it selects it. The mouse wheel
+// moves the current item up and down.
+func (l *List) MouseHandler() func(event *MouseEvent) bool {
+	return func(event *MouseEvent) bool {
+		previousItem := l.currentItem
+
+		switch event.Action {
+		case MouseScrollUp:
+			if l.currentItem > 0 {
+				l.currentItem--
+			}
+		case MouseScrollDown:
+			if l.currentItem < len(l.Items)-1 {
+				l.currentItem++
+			}
+		case MouseClick, MouseDoubleClick:
+			if event.Button != tcell.Button1 {
+				return false
+			}
+			index := l.indexAtPoint(event.Position())
+			if index < 0 {
+				return false
+			}
+			l.currentItem = index
+
+			// A double click selects the item, like the Enter key.
+			if event.Action == MouseDoubleClick {
+				item := l.Items[l.currentItem]
+				if item.Selected != nil {
+					item.Selected()
+				}
+				if l.selected != nil {
+					l.selected(l.currentItem, item.MainText, item.SecondaryText, item.Shortcut)
+				}
+			}
+		default:
+			return false
+		}
+
+		if l.currentItem != previousItem && l.currentItem < len(l.Items) && l.changed != nil {
+			item := l.Items[l.currentItem]
+			l.changed(l.currentItem, item.MainText, item.SecondaryText, item.Shortcut)
+		}
+
+		return true
+	}
+}
@@ -101,6 +101,11 @@ func (m *Modal) AddButtons(labels []string) *Modal {
 	return m
 }
 
+// Children returns the frame embedded in the modal.
+func (m *Modal) Children() []Primitive {
+	return []Primitive{m.Frame}
+}
+
 // Focus is called when this primitive receives focus.
 func (m *Modal) Focus(delegate func(p Primitive)) {
 	delegate(m.Form)
@@ -0,0 +1,319 @@
+package tview
+
+import (
+	"time"
+
+	"github.com/diamondburned/tcell"
+)
+
+// DoubleClickDuration is the maximum time between two clicks for them to be
+// reported as a double click.
+var DoubleClickDuration = 250 * time.Millisecond
+
+// MouseAction describes what the mouse did. It is determined by the
+// application from the raw button states reported by the terminal.
+type MouseAction int
+
+// Mouse actions as delivered in MouseEvent.
+const (
+	MouseMove        MouseAction = iota // The pointer moved while no button was held down.
+	MouseDown                           // A button was pressed.
+	MouseUp                             // A button was released.
+	MouseClick                          // A button was pressed and released on the same primitive without dragging.
+	MouseDoubleClick                    // A click followed another click within DoubleClickDuration.
+	MouseDragStart                      // The pointer started moving while a button was held down.
+	MouseDragMove                       // The pointer moved during a drag.
+	MouseDragEnd                        // The button was released after a drag.
+	MouseHoverEnter                     // The pointer entered the primitive.
+	MouseHoverLeave                     // The pointer left the primitive.
+	MouseScrollUp                       // The mouse wheel was moved up.
+	MouseScrollDown                     // The mouse wheel was moved down.
+	MouseScrollLeft                     // The mouse wheel was moved left.
+	MouseScrollRight                    // The mouse wheel was moved right.
+)
+
+// The buttons (as opposed to wheel motions) of a tcell.ButtonMask.
+const mouseButtons = tcell.Button1 | tcell.Button2 | tcell.Button3 | tcell.Button4 |
+	tcell.Button5 | tcell.Button6 | tcell.Button7 | tcell.Button8
+
+// MouseEvent is a mouse event as it is delivered to primitives (see
+// MouseEventSupport). It embeds the raw tcell event which triggered it.
+//
+// Once a button is pressed over a primitive, that primitive (or the container
+// which consumed the MouseDown event) captures the pointer: It receives all
+// subsequent drag and button release events, even if the pointer leaves it,
+// until the button is released.
+type MouseEvent struct {
+	*tcell.EventMouse
+
+	// What the mouse did.
+	Action MouseAction
+
+	// The button which caused the action, e.g. tcell.Button1 for a left click.
+	// This is tcell.ButtonNone for move and hover events and the wheel motion
+	// for scroll events.
+	Button tcell.ButtonMask
+
+	// The position of the pointer relative to the top-left corner of the
+	// primitive receiving the event. The absolute position is returned by
+	// Position().
+	X, Y int
+
+	// Sets the keyboard focus, like the function passed to a primitive's
+	// input handler.
+	SetFocus func(p Primitive)
+}
+
+// mouseState tracks the mouse between raw mouse events.
+type mouseState struct {
+	// The buttons held down during the last event.
+	buttons tcell.ButtonMask
+
+	// The primitive which captured the pointer when a button was pressed, or
+	// nil if no button is held down. The button and position of that press.
+	capture      Primitive
+	button       tcell.ButtonMask
+	downX, downY int
+
+	// Whether or not the pointer was moved while the button was held down.
+	dragging bool
+
+	// The primitive under the pointer.
+	hover Primitive
+
+	// The last click, used to detect double clicks.
+	lastClick              time.Time
+	lastClickButton        tcell.ButtonMask
+	lastClickTarget        Primitive
+	lastClickX, lastClickY int
+}
+
+// handleMouse turns a raw mouse event into mouse actions and delivers them to
+// the affected primitives. Returns true if the screen needs to be redrawn.
+func (a *Application) handleMouse(event *tcell.EventMouse) (draw bool) {
+	x, y := event.Position()
+
+	a.RLock()
+	path := a.getMousePath(x, y)
+	clickToFocus := a.clickToFocus
+	focusFollowsMouse := a.focusFollowsMouse
+	a.RUnlock()
+
+	// Shorthand for delivering events.
+	fire := func(path []Primitive, action MouseAction, button tcell.ButtonMask, bubble bool) Primitive {
+		consumer, redraw := a.fireMouseEvent(event, path, action, button, bubble)
+		if redraw {
+			draw = true
+		}
+		return consumer
+	}
+
+	// Primitives which handle raw mouse events get them as they are.
+	if fireRawMouseEvent(event, path) {
+		draw = true
+	}
+
+	m := &a.mouse
+	buttons := event.Buttons() & mouseButtons
+	pressed := buttons &^ m.buttons
+	released := m.buttons &^ buttons
+	m.buttons = buttons
+
+	// Hover events are not sent while the pointer is captured.
+	if m.capture == nil {
+		var hover Primitive
+		if len(path) > 0 {
+			hover = path[len(path)-1]
+		}
+		if hover != m.hover {
+			if m.hover != nil {
+				fire(a.pathTo(m.hover), MouseHoverLeave, tcell.ButtonNone, false)
+			}
+			m.hover = hover
+			if hover != nil {
+				fire(path, MouseHoverEnter, tcell.ButtonNone, false)
+				if focusFollowsMouse && a.focusPath(path) {
+					draw = true
+				}
+			}
+		}
+	}
+
+	// Events go to the primitive which captured the pointer, if any.
+	target := path
+	if m.capture != nil {
+		target = a.pathTo(m.capture)
+	}
+
+	// Wheel motions.
+	if wheel := event.Buttons() &^ mouseButtons; wheel != 0 {
+		action := MouseScrollUp
+		switch {
+		case wheel&tcell.WheelDown != 0:
+			action = MouseScrollDown
+		case wheel&tcell.WheelLeft != 0:
+			action = MouseScrollLeft
+		case wheel&tcell.WheelRight != 0:
+			action = MouseScrollRight
+		}
+		fire(target, action, wheel, true)
+		return
+	}
+
+	switch {
+	case pressed != 0 && m.capture == nil && len(path) > 0:
+		// A new press. Determine the pointer capture.
+		button := pressed & -pressed // The lowest button only.
+		if clickToFocus && a.focusPath(path) {
+			draw = true
+		}
+		consumer := fire(path, MouseDown, button, true)
+		if consumer == nil {
+			consumer = path[len(path)-1]
+		}
+		m.capture, m.button = consumer, button
+		m.downX, m.downY = x, y
+		m.dragging = false
+
+	case pressed != 0 && m.capture != nil:
+		// Another button was pressed during a drag.
+		fire(target, MouseDown, pressed&-pressed, false)
+
+	case released&m.button != 0 && m.capture != nil:
+		// The button which captured the pointer was released.
+		if m.dragging {
+			fire(target, MouseDragEnd, m.button, false)
+		}
+		fire(target, MouseUp, m.button, false)
+		if !m.dragging && inRect(m.capture, x, y) {
+			fire(target, MouseClick, m.button, false)
+			if event.When().Sub(m.lastClick) <= DoubleClickDuration &&
+				m.lastClickTarget == m.capture && m.lastClickButton == m.button &&
+				m.lastClickX == x && m.lastClickY == y {
+				fire(target, MouseDoubleClick, m.button, false)
+				m.lastClick = time.Time{} // A third click starts over.
+			} else {
+				m.lastClick = event.When()
+				m.lastClickTarget, m.lastClickButton = m.capture, m.button
+				m.lastClickX, m.lastClickY = x, y
+			}
+		}
+		m.capture = nil
+		m.dragging = false
+
+	case released != 0 && m.capture != nil:
+		// Another button was released during a drag.
+		fire(target, MouseUp, released&-released, false)
+
+	case m.capture != nil:
+		// The pointer moved while the button was held down.
+		if !m.dragging {
+			if x == m.downX && y == m.downY {
+				break
+			}
+			m.dragging = true
+			fire(target, MouseDragStart, m.button, false)
+		}
+		fire(target, MouseDragMove, m.button, false)
+
+	default:
+		fire(target, MouseMove, tcell.ButtonNone, true)
+	}
+
+	return
+}
+
+// pathTo returns the path from the root primitive to the given primitive. If
+// the primitive is not part of the root's tree (e.g. the list of an open
+// drop-down), the path consists of the primitive alone.
+func (a *Application) pathTo(p Primitive) []Primitive {
+	a.RLock()
+	root := a.root
+	a.RUnlock()
+
+	if path := getPrimitivePath(root, p); path != nil {
+		return path
+	}
+	return []Primitive{p}
+}
+
+// fireMouseEvent delivers a mouse action to the given path of primitives. The
+// capture functions along the path are called first, top-down. The event is
+// then handed to the last primitive of the path and, if "bubble" is true and
+// that primitive doesn't consume it, to its containers. Returns the primitive
+// which consumed the event (nil if none did) and whether the screen needs to be
+// redrawn.
+func (a *Application) fireMouseEvent(raw *tcell.EventMouse, path []Primitive, action MouseAction, button tcell.ButtonMask, bubble bool) (consumer Primitive, draw bool) {
+	if len(path) == 0 {
+		return nil, false
+	}
+
+	x, y := raw.Position()
+	event := &MouseEvent{
+		EventMouse: raw,
+		Action:     action,
+		Button:     button,
+		SetFocus:   a.SetFocus,
+	}
+
+	// Let the capture functions intercept the event, top-down.
+	for _, p := range path {
+		capturer, ok := p.(mouseCapturer)
+		if !ok {
+			continue
+		}
+		if capture := capturer.GetMouseCapture(); capture != nil {
+			rectX, rectY, _, _ := p.GetRect()
+			event.X, event.Y = x-rectX, y-rectY
+			if event = capture(event); event == nil {
+				return nil, true // Don't forward event.
+			}
+		}
+	}
+
+	// Hand the event to the mouse handlers, bottom-up.
+	x, y = event.Position()
+	for index := len(path) - 1; index >= 0; index-- {
+		if mouseSupport, ok := path[index].(MouseEventSupport); ok {
+			if handler := mouseSupport.MouseHandler(); handler != nil {
+				rectX, rectY, _, _ := path[index].GetRect()
+				local := *event
+				local.X, local.Y = x-rectX, y-rectY
+				if handler(&local) {
+					return path[index], true
+				}
+			}
+		}
+		if !bubble {
+			break
+		}
+	}
+
+	return nil, false
+}
+
+// fireRawMouseEvent hands a raw mouse event to the innermost primitive of the
+// given path which implements MouseSupport. Returns true if the screen needs to
+// be redrawn.
+func fireRawMouseEvent(event *tcell.EventMouse, path []Primitive) bool {
+	for index := len(path) - 1; index >= 0; index-- {
+		if mouseSupport, ok := path[index].(MouseSupport); ok {
+			if handler := mouseSupport.MouseHandler(); handler != nil {
+				return handler(event)
+			}
+		}
+	}
+	return false
+}
+
+// hasMouseHandler returns whether or not the given primitive handles mouse
+// events, either as mouse actions or as raw events.
+func hasMouseHandler(p Primitive) bool {
+	switch p := p.(type) {
+	case MouseEventSupport:
+		return p.MouseHandler() != nil
+	case MouseSupport:
+		return p.MouseHandler() != nil
+	}
+	return false
+}
@@ -205,6 +205,18 @@ func (p *Pages) SendToBack(name string) *Pages {
 	return p
 }
 
+// Children returns the primitives of all visible pages, in the order in which
+// they are drawn.
+func (p *Pages) Children() []Primitive {
+	var children []Primitive
+	for _, page := range p.pages {
+		if page.Visible {
+			children = append(children, page.Item)
+		}
+	}
+	return children
+}
+
 // HasFocus returns whether or not this primitive has focus.
 func (p *Pages) HasFocus() bool {
 	for _, page := range p.pages {
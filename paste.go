@@ -0,0 +1,254 @@
+package tview
+
+import (
+	"strings"
+	"time"
+
+	"github.com/diamondburned/tcell"
+)
+
+// PasteTimeout is the time the application waits for the remainder of a
+// bracketed paste marker after receiving its first part. If the marker is not
+// completed in time, the keys received so far are processed as regular key
+// events.
+var PasteTimeout = 50 * time.Millisecond
+
+// The escape sequences which enable and disable bracketed paste mode.
+const (
+	pasteEnable  = "\x1b[?2004h"
+	pasteDisable = "\x1b[?2004l"
+)
+
+// The markers which surround pasted text, minus the leading "ESC [". tcell
+// doesn't know them, so they arrive as Alt-[ followed by these runes.
+var (
+	pasteStartMarker = []rune("200~")
+	pasteEndMarker   = []rune("201~")
+)
+
+// The final runes of the terminal's focus reports, "ESC [ I" and "ESC [ O",
+// which arrive in the same way.
+const (
+	focusInMarker  = 'I'
+	focusOutMarker = 'O'
+)
+
+// EventPaste is an event which carries text that was pasted into the terminal
+// as a whole. Applications receive it instead of individual key events if the
+// terminal supports bracketed paste. It is handed to the focused primitive if
+// that primitive implements PasteSupport. Otherwise, the text is replayed as
+// key events.
+type EventPaste struct {
+	t    time.Time
+	text string
+}
+
+// NewEventPaste returns a new paste event carrying the given text. It may be
+// sent to the application with QueueEvent() to simulate a paste.
+func NewEventPaste(text string) *EventPaste {
+	return &EventPaste{t: time.Now(), text: text}
+}
+
+// When returns the time when the paste was completed.
+func (e *EventPaste) When() time.Time {
+	return e.t
+}
+
+// Text returns the pasted text. Line breaks are normalized to "\n".
+func (e *EventPaste) Text() string {
+	return e.text
+}
+
+// keys returns the pasted text as key events, as they would have been
+// received without bracketed paste.
+func (e *EventPaste) keys() []*tcell.EventKey {
+	keys := make([]*tcell.EventKey, 0, len(e.text))
+	for _, r := range e.text {
+		switch r {
+		case '\n':
+			keys = append(keys, tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+		case '\t':
+			keys = append(keys, tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone))
+		default:
+			keys = append(keys, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		}
+	}
+	return keys
+}
+
+// pasteTimeoutEvent is queued when the remainder of a paste start marker did
+// not arrive within PasteTimeout.
+type pasteTimeoutEvent struct {
+	t time.Time
+
+	// The pasteDecoder.generation at the time the timer was started.
+	generation int
+}
+
+// When returns the time the timeout occurred.
+func (e *pasteTimeoutEvent) When() time.Time {
+	return e.t
+}
+
+// The states of the paste decoder.
+const (
+	pasteIdle  = iota // Regular key input.
+	pasteStart        // Matching the paste start marker.
+	pasteText         // Collecting pasted text.
+	pasteEnd          // Matching the paste end marker.
+)
+
+// pasteDecoder assembles key events surrounded by bracketed paste markers into
+// paste events. It also recognizes the terminal's focus reports, which start
+// like the markers.
+type pasteDecoder struct {
+	// One of the paste states.
+	state int
+
+	// The number of marker runes matched so far.
+	matched int
+
+	// The key events held back while matching a marker.
+	pending []*tcell.EventKey
+
+	// The pasted text collected so far.
+	text strings.Builder
+
+	// Whether the last key added to the text was a carriage return, so that
+	// "\r\n" results in a single line break.
+	cr bool
+
+	// Incremented whenever a start marker is encountered so stale timeouts can
+	// be ignored.
+	generation int
+}
+
+// isMarker returns true if the key event is the beginning of a paste marker.
+func (p *pasteDecoder) isMarker(event *tcell.EventKey) bool {
+	return event.Key() == tcell.KeyRune && event.Rune() == '[' && event.Modifiers() == tcell.ModAlt
+}
+
+// matches returns true if the key event is the next rune of the given marker.
+func (p *pasteDecoder) matches(event *tcell.EventKey, marker []rune) bool {
+	return event.Key() == tcell.KeyRune && event.Modifiers() == tcell.ModNone && event.Rune() == marker[p.matched]
+}
+
+// decode feeds a key event into the decoder. It returns the key events which
+// are to be processed regularly (this may include events held back earlier)
+// and, once the end of a paste was reached, the resulting paste event. A focus
+// report of the terminal results in an EventTerminalFocus instead. "started" is
+// true if a start marker was encountered and the caller needs to schedule a
+// timeout.
+func (p *pasteDecoder) decode(event *tcell.EventKey) (keys []*tcell.EventKey, decoded tcell.Event, started bool) {
+	switch p.state {
+	case pasteIdle:
+		if !p.isMarker(event) {
+			return []*tcell.EventKey{event}, nil, false
+		}
+		p.state, p.matched = pasteStart, 0
+		p.pending = append(p.pending[:0], event)
+		p.generation++
+		return nil, nil, true
+
+	case pasteStart:
+		if p.matched == 0 && event.Key() == tcell.KeyRune && event.Modifiers() == tcell.ModNone &&
+			(event.Rune() == focusInMarker || event.Rune() == focusOutMarker) {
+			// A focus report.
+			p.state = pasteIdle
+			p.pending = p.pending[:0]
+			return nil, &EventTerminalFocus{t: event.When(), focused: event.Rune() == focusInMarker}, false
+		}
+		if !p.matches(event, pasteStartMarker) {
+			// Not a paste after all.
+			keys = p.flush(p.generation)
+			more, decoded, started := p.decode(event)
+			return append(keys, more...), decoded, started
+		}
+		p.pending = append(p.pending, event)
+		if p.matched++; p.matched == len(pasteStartMarker) {
+			p.state = pasteText
+			p.pending = p.pending[:0]
+			p.text.Reset()
+			p.cr = false
+		}
+
+	case pasteText:
+		if p.isMarker(event) {
+			p.state, p.matched = pasteEnd, 0
+			p.pending = append(p.pending[:0], event)
+			return
+		}
+		p.add(event)
+
+	case pasteEnd:
+		if !p.matches(event, pasteEndMarker) {
+			// The held back keys were part of the pasted text.
+			for _, key := range p.pending {
+				p.add(key)
+			}
+			p.state = pasteText
+			p.pending = p.pending[:0]
+			return p.decode(event)
+		}
+		p.pending = append(p.pending, event)
+		if p.matched++; p.matched == len(pasteEndMarker) {
+			decoded = &EventPaste{t: event.When(), text: p.text.String()}
+			p.state = pasteIdle
+			p.pending = p.pending[:0]
+			p.text.Reset()
+		}
+	}
+
+	return
+}
+
+// flush returns the key events held back while matching a paste start marker
+// and resets the decoder, provided that the marker was started in the given
+// generation. This is used when the marker turned out not to be one.
+func (p *pasteDecoder) flush(generation int) []*tcell.EventKey {
+	if p.state != pasteStart || generation != p.generation {
+		return nil
+	}
+	keys := make([]*tcell.EventKey, len(p.pending))
+	copy(keys, p.pending)
+	p.state = pasteIdle
+	p.pending = p.pending[:0]
+	return keys
+}
+
+// add adds a key event received during a paste to the pasted text.
+func (p *pasteDecoder) add(event *tcell.EventKey) {
+	cr := p.cr
+	p.cr = false
+	switch event.Key() {
+	case tcell.KeyRune:
+		if event.Modifiers()&tcell.ModAlt != 0 {
+			p.text.WriteRune('\x1b')
+		}
+		p.text.WriteRune(event.Rune())
+	case tcell.KeyEnter:
+		p.text.WriteRune('\n')
+		p.cr = true
+	case tcell.KeyLF:
+		if !cr {
+			p.text.WriteRune('\n')
+		}
+	case tcell.KeyTab:
+		p.text.WriteRune('\t')
+	}
+}
+
+// setPasteMode asks the terminal to enable or disable bracketed paste mode.
+// Returns true if the screen supports sending escape sequences to the terminal.
+func setPasteMode(screen tcell.Screen, enable bool) bool {
+	terminal, ok := screen.(interface{ TPuts(string) })
+	if !ok {
+		return false
+	}
+	if enable {
+		terminal.TPuts(pasteEnable)
+	} else {
+		terminal.TPuts(pasteDisable)
+	}
+	return true
+}
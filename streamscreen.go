@@ -0,0 +1,1302 @@
+package tview
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/diamondburned/tcell"
+	"github.com/diamondburned/tcell/terminfo"
+)
+
+// ErrStreamClosed is returned by StreamScreen.Init() when the input stream of
+// the screen has ended.
+var ErrStreamClosed = errors.New("stream closed")
+
+// The escape sequence which starts a hyperlink to the given URL or, if it is
+// empty, ends the current hyperlink (OSC 8).
+const streamHyperlink = "\x1b]8;;%s\x1b\\"
+
+// StreamScreen is a tcell.Screen which drives a terminal on the other end of an
+// arbitrary io.ReadWriter, e.g. an SSH channel or a network connection. Output
+// is written using the escape sequences of the given terminal type. Input is
+// read from the stream and decoded into key, mouse, paste, and terminal focus
+// events. As streams carry no window size information, the size of the
+// terminal must be reported with SetSize(), e.g. when an SSH "window-change"
+// request arrives.
+//
+// Each StreamScreen is used by one Application:
+//
+//   screen, err := tview.NewStreamScreen(channel, "xterm-256color")
+//   if err != nil {
+//       return err
+//   }
+//   screen.SetSize(width, height)
+//   if err := screen.Init(); err != nil {
+//       return err
+//   }
+//   app := tview.NewApplication()
+//   app.SetScreen(screen)
+//   app.SetRoot(root, true)
+//   return app.Run()
+//
+// The application stops when the input stream ends (i.e. when reading from it
+// returns an error such as io.EOF). It also stops when writing to the stream
+// fails, in which case Application.Run() returns the write error.
+type StreamScreen struct {
+	sync.Mutex
+
+	// The stream connected to the terminal.
+	stream io.ReadWriter
+
+	// The terminal description.
+	terminfo *terminfo.Terminfo
+
+	// The screen content.
+	cells tcell.CellBuffer
+
+	// The hyperlink targets of cells as set with SetHyperlink() and as shown on
+	// the terminal, indexed by their position, and the hyperlink target
+	// currently set on the terminal.
+	links, drawnLinks map[[2]int]string
+	currentLink       string
+
+	// The size of the terminal.
+	width, height int
+
+	// The default style and the style currently set on the terminal.
+	style, currentStyle tcell.Style
+
+	// The position of the terminal's cursor (-1 if unknown) and where the
+	// cursor is to be shown (-1 if hidden).
+	cursorX, cursorY int
+	showX, showY     int
+
+	// Whether or not the terminal needs to be cleared on the next Show().
+	clear bool
+
+	// Whether or not the terminal supports 24-bit colors. If not, colors are
+	// mapped to the palette.
+	trueColor bool
+	palette   []tcell.Color
+	colors    map[tcell.Color]tcell.Color
+
+	// Whether or not mouse events were requested.
+	mouse bool
+
+	// Fallback strings for runes the terminal can't display.
+	fallback map[rune]string
+
+	// The output of the current Show() call, written in one piece.
+	output bytes.Buffer
+
+	// The error which occurred when writing to the stream, if any. No more
+	// output is written after an error.
+	err error
+
+	// Decoded events. "quit" is closed when the screen is finalized, "done" is
+	// closed when the input stream has ended.
+	events chan tcell.Event
+	quit   chan struct{}
+	done   chan struct{}
+
+	// Whether or not the screen was initialized and is not finalized.
+	initialized bool
+
+	// Makes sure the input goroutines are only started once.
+	startInput sync.Once
+
+	// The input decoder.
+	parser streamParser
+
+	// The minimum number of lines an inline screen occupies (0 if the screen
+	// uses the whole terminal), the number of lines it currently occupies,
+	// and the line the terminal's cursor is on, counted from the screen's
+	// first line (see NewInlineScreen()).
+	inline, lines, line int
+
+	// The terminal of an inline screen running on the process's terminal.
+	tty *ttyStream
+}
+
+// NewStreamScreen returns a new screen which drives a terminal of the given type
+// (e.g. "xterm-256color", as sent in an SSH "pty-req" request) over the given
+// stream. The initial size is the default size of the terminal type, usually
+// 80x24. Use SetSize() to change it.
+func NewStreamScreen(stream io.ReadWriter, term string) (*StreamScreen, error) {
+	ti, err := terminfo.LookupTerminfo(term)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &StreamScreen{
+		stream:   stream,
+		terminfo: ti,
+		width:    ti.Columns,
+		height:   ti.Lines,
+		style:    tcell.StyleDefault,
+		fallback: make(map[rune]string),
+		events:   make(chan tcell.Event, 10),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+		cursorX:  -1,
+		cursorY:  -1,
+		showX:    -1,
+		showY:    -1,
+	}
+	if s.width <= 0 {
+		s.width = 80
+	}
+	if s.height <= 0 {
+		s.height = 24
+	}
+	close(s.quit) // Not initialized yet.
+
+	s.trueColor = ti.SetFgBgRGB != "" || ti.SetFgRGB != "" || ti.SetBgRGB != ""
+	if !s.trueColor {
+		s.colors = make(map[tcell.Color]tcell.Color)
+		s.palette = make([]tcell.Color, ti.Colors)
+		for index := range s.palette {
+			s.palette[index] = tcell.Color(index)
+			s.colors[tcell.Color(index)] = tcell.Color(index)
+		}
+	}
+	s.parser.prepare(ti)
+
+	return s, nil
+}
+
+// Init initializes the terminal. It may be called again after Fini().
+func (s *StreamScreen) Init() error {
+	select {
+	case <-s.done:
+		return ErrStreamClosed
+	default:
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.initialized {
+		return nil
+	}
+	if s.tty != nil {
+		if err := s.tty.enter(s); err != nil {
+			return err
+		}
+	}
+	s.initialized = true
+	s.quit = make(chan struct{})
+
+	ti := s.terminfo
+	if s.inline > 0 {
+		// Start on the cursor's line, below what's on the terminal already.
+		s.lines, s.line = 0, 0
+		s.puts(ti.HideCursor)
+		s.puts(ti.EnterKeypad)
+		s.puts(ti.EnableAcs)
+		s.grow(s.inline)
+	} else {
+		s.puts(ti.EnterCA)
+		s.puts(ti.HideCursor)
+		s.puts(ti.EnterKeypad)
+		s.puts(ti.EnableAcs)
+		s.puts(ti.Clear)
+	}
+	s.cells.Resize(s.width, s.height)
+	s.cells.Invalidate()
+	s.drawnLinks = nil
+	s.cursorX, s.cursorY = -1, -1
+	s.currentStyle = tcell.Style(-1)
+	if s.mouse {
+		s.setMouse(true)
+	}
+	s.flush()
+
+	s.startInput.Do(func() {
+		go s.inputLoop()
+	})
+
+	return nil
+}
+
+// Fini restores the terminal. PollEvent() returns nil afterwards.
+func (s *StreamScreen) Fini() {
+	s.Lock()
+	defer s.Unlock()
+
+	if !s.initialized {
+		return
+	}
+	s.initialized = false
+	close(s.quit)
+
+	ti := s.terminfo
+	if s.inline > 0 {
+		// Leave the last frame on the terminal and continue below it.
+		s.puts(ti.AttrOff)
+		s.moveTo(0, s.lines-1)
+		s.output.WriteString("\r\n")
+		s.puts(ti.ShowCursor)
+		s.puts(ti.ExitKeypad)
+	} else {
+		s.puts(ti.ShowCursor)
+		s.puts(ti.AttrOff)
+		s.puts(ti.Clear)
+		s.puts(ti.ExitCA)
+		s.puts(ti.ExitKeypad)
+		if s.mouse {
+			s.setMouse(false)
+		}
+	}
+	s.flush()
+
+	if s.tty != nil {
+		s.tty.leave()
+	}
+}
+
+// SetSize sets the size of the terminal, e.g. after an SSH "window-change"
+// request, and sends a resize event to the application. It does not wait for
+// the event to be processed so it may also be called from the application's
+// event loop.
+func (s *StreamScreen) SetSize(width, height int) {
+	s.Lock()
+	defer s.Unlock()
+
+	if width == s.width && height == s.height {
+		return
+	}
+	s.resize(width, height)
+	s.postEvent(tcell.NewEventResize(width, height))
+}
+
+// resize changes the size of the terminal. The screen must be locked.
+func (s *StreamScreen) resize(width, height int) {
+	s.width, s.height = width, height
+	s.cells.Resize(width, height)
+	s.cells.Invalidate()
+	s.drawnLinks = nil
+	s.clear = true
+	s.cursorX, s.cursorY = -1, -1
+	if s.lines > height {
+		s.lines = height
+	}
+}
+
+// TPuts writes the given escape sequence to the terminal.
+func (s *StreamScreen) TPuts(str string) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.puts(str)
+	s.flush()
+}
+
+// Clear clears the screen content.
+func (s *StreamScreen) Clear() {
+	s.Lock()
+	s.cells.Fill(' ', s.style)
+	s.links = nil
+	s.Unlock()
+}
+
+// Fill fills the screen with the given rune and style.
+func (s *StreamScreen) Fill(r rune, style tcell.Style) {
+	s.Lock()
+	s.cells.Fill(r, style)
+	s.links = nil
+	s.Unlock()
+}
+
+// SetCell is an older API which sets the content of a cell.
+func (s *StreamScreen) SetCell(x, y int, style tcell.Style, ch ...rune) {
+	if len(ch) > 0 {
+		s.SetContent(x, y, ch[0], ch[1:], style)
+	} else {
+		s.SetContent(x, y, ' ', nil, style)
+	}
+}
+
+// GetContent returns the content of a cell.
+func (s *StreamScreen) GetContent(x, y int) (mainc rune, combc []rune, style tcell.Style, width int) {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.cells.GetContent(x, y)
+}
+
+// SetContent sets the content of a cell. This removes the cell's hyperlink.
+func (s *StreamScreen) SetContent(x, y int, mainc rune, combc []rune, style tcell.Style) {
+	s.Lock()
+	s.cells.SetContent(x, y, mainc, combc, style)
+	delete(s.links, [2]int{x, y})
+	s.Unlock()
+}
+
+// SetHyperlink turns the content of a cell into a hyperlink to the given URL
+// which terminals supporting hyperlinks (using the OSC 8 escape sequence) let
+// the user open. Set the cell's content with SetContent() first. Provide an
+// empty string to remove the hyperlink.
+func (s *StreamScreen) SetHyperlink(x, y int, url string) {
+	url = strings.Map(func(r rune) rune {
+		if r < ' ' || r == '\x7f' {
+			return -1
+		}
+		return r
+	}, url)
+
+	s.Lock()
+	defer s.Unlock()
+
+	if url == "" {
+		delete(s.links, [2]int{x, y})
+		return
+	}
+	if s.links == nil {
+		s.links = make(map[[2]int]string)
+	}
+	s.links[[2]int{x, y}] = url
+}
+
+// SetStyle sets the default style.
+func (s *StreamScreen) SetStyle(style tcell.Style) {
+	s.Lock()
+	s.style = style
+	s.Unlock()
+}
+
+// ShowCursor shows the cursor at the given position on the next Show().
+func (s *StreamScreen) ShowCursor(x, y int) {
+	s.Lock()
+	s.showX, s.showY = x, y
+	s.Unlock()
+}
+
+// HideCursor hides the cursor on the next Show().
+func (s *StreamScreen) HideCursor() {
+	s.ShowCursor(-1, -1)
+}
+
+// Size returns the size of the terminal.
+func (s *StreamScreen) Size() (int, int) {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.width, s.height
+}
+
+// PollEvent waits for the next event. It returns nil once the screen was
+// finalized.
+func (s *StreamScreen) PollEvent() tcell.Event {
+	s.Lock()
+	quit := s.quit
+	s.Unlock()
+
+	select {
+	case <-quit:
+		return nil
+	case event := <-s.events:
+		return event
+	}
+}
+
+// PostEvent sends an event to the event queue. It returns an error if the
+// queue is full.
+func (s *StreamScreen) PostEvent(event tcell.Event) error {
+	select {
+	case s.events <- event:
+		return nil
+	default:
+		return tcell.ErrEventQFull
+	}
+}
+
+// PostEventWait sends an event to the event queue, waiting for space if the
+// queue is full. The event is dropped if the screen is not initialized or is
+// finalized while waiting.
+func (s *StreamScreen) PostEventWait(event tcell.Event) {
+	s.Lock()
+	quit := s.quit
+	s.Unlock()
+
+	select {
+	case s.events <- event:
+	case <-quit:
+	}
+}
+
+// postEvent sends an event to the event queue without waiting for space in the
+// queue. If the queue is full, the event is sent in the background and dropped
+// if the screen is finalized first. The screen must be locked.
+func (s *StreamScreen) postEvent(event tcell.Event) {
+	select {
+	case s.events <- event:
+		return
+	default:
+	}
+
+	quit := s.quit
+	go func() {
+		select {
+		case s.events <- event:
+		case <-quit:
+		}
+	}()
+}
+
+// EnableMouse asks the terminal to report mouse events.
+func (s *StreamScreen) EnableMouse() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.mouse = true
+	s.setMouse(true)
+	s.flush()
+}
+
+// DisableMouse asks the terminal to stop reporting mouse events.
+func (s *StreamScreen) DisableMouse() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.mouse = false
+	s.setMouse(false)
+	s.flush()
+}
+
+// HasMouse returns true as mouse support is requested from all terminals,
+// except for inline screens.
+func (s *StreamScreen) HasMouse() bool {
+	return s.inline == 0
+}
+
+// Colors returns the number of colors the terminal supports.
+func (s *StreamScreen) Colors() int {
+	if s.trueColor {
+		return 1 << 24
+	}
+	return s.terminfo.Colors
+}
+
+// Show writes the changes made to the screen content since the last call to
+// the terminal.
+func (s *StreamScreen) Show() {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.initialized {
+		s.draw()
+		s.flush()
+	}
+}
+
+// Sync redraws the whole terminal.
+func (s *StreamScreen) Sync() {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.initialized {
+		s.clear = true
+		s.cells.Invalidate()
+		s.drawnLinks = nil
+		s.draw()
+		s.flush()
+	}
+}
+
+// CharacterSet returns the character set of the terminal, which is always
+// UTF-8.
+func (s *StreamScreen) CharacterSet() string {
+	return "UTF-8"
+}
+
+// RegisterRuneFallback sets a string to be displayed instead of the given rune
+// if the terminal can't display it.
+func (s *StreamScreen) RegisterRuneFallback(r rune, subst string) {
+	s.Lock()
+	s.fallback[r] = subst
+	s.Unlock()
+}
+
+// UnregisterRuneFallback removes a fallback set with RegisterRuneFallback().
+func (s *StreamScreen) UnregisterRuneFallback(r rune) {
+	s.Lock()
+	delete(s.fallback, r)
+	s.Unlock()
+}
+
+// CanDisplay returns true as UTF-8 terminals are assumed to be able to display
+// all runes.
+func (s *StreamScreen) CanDisplay(r rune, checkFallbacks bool) bool {
+	return true
+}
+
+// Resize does nothing. Use SetSize() to change the size of the terminal.
+func (s *StreamScreen) Resize(int, int, int, int) {}
+
+// HasKey returns true if the terminal is known to send the given key.
+func (s *StreamScreen) HasKey(key tcell.Key) bool {
+	if key == tcell.KeyRune {
+		return true
+	}
+	return s.parser.has[key]
+}
+
+// puts writes an escape sequence to the output buffer. The screen must be
+// locked.
+func (s *StreamScreen) puts(str string) {
+	if str != "" {
+		s.terminfo.TPuts(&s.output, str, 0)
+	}
+}
+
+// flush writes the output buffer to the stream. If writing fails, the
+// application is stopped with the error. The screen must be locked.
+func (s *StreamScreen) flush() {
+	if s.output.Len() > 0 && s.err == nil {
+		if _, err := s.stream.Write(s.output.Bytes()); err != nil {
+			s.err = err
+			s.postEvent(&streamEndEvent{t: time.Now(), err: err})
+		}
+	}
+	s.output.Reset()
+}
+
+// setMouse writes the escape sequence to enable or disable mouse reporting to
+// the output buffer. The screen must be locked.
+func (s *StreamScreen) setMouse(enable bool) {
+	if s.inline > 0 {
+		return // Mouse coordinates are relative to the whole terminal.
+	}
+	if s.terminfo.MouseMode != "" {
+		if enable {
+			s.puts(s.terminfo.TParm(s.terminfo.MouseMode, 1))
+		} else {
+			s.puts(s.terminfo.TParm(s.terminfo.MouseMode, 0))
+		}
+		return
+	}
+	if enable {
+		s.output.WriteString("\x1b[?1000h\x1b[?1002h\x1b[?1003h\x1b[?1006h")
+	} else {
+		s.output.WriteString("\x1b[?1006l\x1b[?1003l\x1b[?1002l\x1b[?1000l")
+	}
+}
+
+// draw writes all changed cells to the output buffer. The screen must be
+// locked.
+func (s *StreamScreen) draw() {
+	ti := s.terminfo
+
+	// Hide the cursor while drawing.
+	s.cursorX, s.cursorY = -1, -1
+	s.puts(ti.HideCursor)
+
+	// Inline screens only draw the lines they occupy, growing if needed.
+	height := s.height
+	if s.inline > 0 {
+		s.grow(s.usedLines())
+		height = s.lines
+	}
+
+	if s.clear {
+		fg, bg, _ := s.style.Decompose()
+		s.setColors(fg, bg)
+		if s.inline > 0 {
+			s.moveTo(0, 0)
+			s.output.WriteString(inlineClearBelow)
+		} else {
+			s.puts(ti.Clear)
+		}
+		s.clear = false
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < s.width; x++ {
+			width := s.drawCell(x, y)
+			if width > 1 && x+1 < s.width {
+				// Make sure the cell is drawn again when the wide
+				// character goes away.
+				s.cells.SetDirty(x+1, y, true)
+			}
+			if width > 1 {
+				x += width - 1
+			}
+		}
+	}
+
+	// End the last hyperlink.
+	if s.currentLink != "" {
+		fmt.Fprintf(&s.output, streamHyperlink, "")
+		s.currentLink = ""
+	}
+
+	// Restore the cursor.
+	if s.showX >= 0 && s.showY >= 0 && s.showX < s.width && s.showY < height {
+		s.moveTo(s.showX, s.showY)
+		s.puts(ti.ShowCursor)
+		s.cursorX, s.cursorY = s.showX, s.showY
+	}
+}
+
+// drawCell writes a single cell to the output buffer if it has changed and
+// returns its width. The screen must be locked.
+func (s *StreamScreen) drawCell(x, y int) int {
+	ti := s.terminfo
+
+	mainc, combc, style, width := s.cells.GetContent(x, y)
+	position := [2]int{x, y}
+	link := s.links[position]
+	if !s.cells.Dirty(x, y) && link == s.drawnLinks[position] {
+		return width
+	}
+
+	if s.cursorX != x || s.cursorY != y {
+		s.moveTo(x, y)
+		s.cursorX, s.cursorY = x, y
+	}
+
+	if style == tcell.StyleDefault {
+		style = s.style
+	}
+	if style != s.currentStyle {
+		fg, bg, attrs := style.Decompose()
+		s.puts(ti.AttrOff)
+		s.setColors(fg, bg)
+		if attrs&tcell.AttrBold != 0 {
+			s.puts(ti.Bold)
+		}
+		if attrs&tcell.AttrItalic != 0 {
+			s.puts(ti.Italic)
+		}
+		if attrs&tcell.AttrStrikethrough != 0 {
+			s.puts(ti.Strikethrough)
+		}
+		if attrs&tcell.AttrUnderline != 0 {
+			s.puts(ti.Underline)
+		}
+		if attrs&tcell.AttrReverse != 0 {
+			s.puts(ti.Reverse)
+		}
+		if attrs&tcell.AttrBlink != 0 {
+			s.puts(ti.Blink)
+		}
+		if attrs&tcell.AttrDim != 0 {
+			s.puts(ti.Dim)
+		}
+		s.currentStyle = style
+	}
+
+	if width < 1 {
+		width = 1
+	}
+	if x > s.width-width {
+		// A wide character which doesn't fit. Print a space instead.
+		width = 1
+		mainc, combc = ' ', nil
+	}
+
+	if link != s.currentLink {
+		fmt.Fprintf(&s.output, streamHyperlink, link)
+		s.currentLink = link
+	}
+	if link != "" {
+		if s.drawnLinks == nil {
+			s.drawnLinks = make(map[[2]int]string)
+		}
+		s.drawnLinks[position] = link
+	} else {
+		delete(s.drawnLinks, position)
+	}
+
+	if fallback, ok := s.fallback[mainc]; ok {
+		s.output.WriteString(fallback)
+	} else {
+		if mainc < ' ' {
+			mainc = ' '
+		}
+		s.output.WriteRune(mainc)
+		for _, r := range combc {
+			s.output.WriteRune(r)
+		}
+	}
+	s.cells.SetDirty(x, y, false)
+
+	s.cursorX += width
+	if width > 1 {
+		// Terminals don't agree on where the cursor is after a wide
+		// character.
+		s.cursorX = -1
+	}
+
+	return width
+}
+
+// moveTo writes the escape sequence which moves the terminal's cursor to the
+// given position to the output buffer. The screen must be locked.
+func (s *StreamScreen) moveTo(x, y int) {
+	if s.inline == 0 {
+		s.puts(s.terminfo.TGoto(x, y))
+		return
+	}
+
+	// Inline screens don't know where they are on the terminal, so the
+	// cursor is moved relative to its current position.
+	if y < s.line {
+		fmt.Fprintf(&s.output, inlineUp, s.line-y)
+	} else if y > s.line {
+		fmt.Fprintf(&s.output, inlineDown, y-s.line)
+	}
+	s.line = y
+	s.output.WriteByte('\r')
+	if x > 0 {
+		fmt.Fprintf(&s.output, inlineRight, x)
+	}
+}
+
+// setColors writes the escape sequences for the given colors to the output
+// buffer. The screen must be locked.
+func (s *StreamScreen) setColors(fg, bg tcell.Color) {
+	ti := s.terminfo
+	if ti.Colors == 0 {
+		return
+	}
+
+	if s.trueColor {
+		if ti.SetFgBgRGB != "" && fg != tcell.ColorDefault && bg != tcell.ColorDefault {
+			r1, g1, b1 := fg.RGB()
+			r2, g2, b2 := bg.RGB()
+			s.puts(ti.TParm(ti.SetFgBgRGB, int(r1), int(g1), int(b1), int(r2), int(g2), int(b2)))
+			return
+		}
+		if fg != tcell.ColorDefault && ti.SetFgRGB != "" {
+			r, g, b := fg.RGB()
+			s.puts(ti.TParm(ti.SetFgRGB, int(r), int(g), int(b)))
+		}
+		if bg != tcell.ColorDefault && ti.SetBgRGB != "" {
+			r, g, b := bg.RGB()
+			s.puts(ti.TParm(ti.SetBgRGB, int(r), int(g), int(b)))
+		}
+		return
+	}
+
+	fg, bg = s.paletteColor(fg), s.paletteColor(bg)
+	if ti.SetFgBg != "" && fg != tcell.ColorDefault && bg != tcell.ColorDefault {
+		s.puts(ti.TParm(ti.SetFgBg, int(fg), int(bg)))
+		return
+	}
+	if fg != tcell.ColorDefault && ti.SetFg != "" {
+		s.puts(ti.TParm(ti.SetFg, int(fg)))
+	}
+	if bg != tcell.ColorDefault && ti.SetBg != "" {
+		s.puts(ti.TParm(ti.SetBg, int(bg)))
+	}
+}
+
+// paletteColor returns the palette color closest to the given color. The
+// screen must be locked.
+func (s *StreamScreen) paletteColor(color tcell.Color) tcell.Color {
+	if color == tcell.ColorDefault {
+		return color
+	}
+	if mapped, ok := s.colors[color]; ok {
+		return mapped
+	}
+	mapped := tcell.FindColor(color, s.palette)
+	s.colors[color] = mapped
+	return mapped
+}
+
+// inputLoop reads from the stream and decodes the input into events until
+// reading fails.
+func (s *StreamScreen) inputLoop() {
+	input := make(chan []byte)
+	go func() {
+		defer close(input)
+		for {
+			buffer := make([]byte, 4096)
+			n, err := s.stream.Read(buffer)
+			if n > 0 {
+				input <- buffer[:n]
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	// An escape key is only reported if no other input follows it shortly.
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+
+	for {
+		select {
+		case data, ok := <-input:
+			if !ok {
+				// The stream has ended. Stop the application.
+				close(s.done)
+				s.PostEventWait(&streamEndEvent{t: time.Now()})
+				return
+			}
+			s.parser.buffer = append(s.parser.buffer, data...)
+			s.postEvents(false)
+			if len(s.parser.buffer) > 0 {
+				timer.Reset(50 * time.Millisecond)
+			}
+
+		case <-timer.C:
+			s.postEvents(true)
+		}
+	}
+}
+
+// postEvents decodes the buffered input and posts the resulting events. If
+// "expire" is true, incomplete escape sequences are decoded as regular keys.
+func (s *StreamScreen) postEvents(expire bool) {
+	s.Lock()
+	width, height := s.width, s.height
+	s.Unlock()
+
+	for _, event := range s.parser.parse(expire, width, height) {
+		s.PostEventWait(event)
+	}
+}
+
+// streamEndEvent is posted by a StreamScreen when its input stream has ended or
+// when writing to the stream failed. The application stops when it receives it.
+type streamEndEvent struct {
+	t time.Time
+
+	// The error which occurred when writing to the stream, if any.
+	err error
+}
+
+// When returns the time the stream ended.
+func (e *streamEndEvent) When() time.Time {
+	return e.t
+}
+
+// streamKey is a key produced by an escape sequence.
+type streamKey struct {
+	key tcell.Key
+	mod tcell.ModMask
+}
+
+// streamParser decodes terminal input into tcell events.
+type streamParser struct {
+	// The escape sequences sent by the terminal for special keys, the proper
+	// prefixes of these sequences, and the length of the longest sequence.
+	keys     map[string]streamKey
+	prefixes map[string]bool
+	longest  int
+
+	// The keys which can be produced by the terminal.
+	has map[tcell.Key]bool
+
+	// Input which has not been decoded yet.
+	buffer []byte
+
+	// Whether or not the last byte was an escape prefixing the next key with
+	// Alt.
+	escaped bool
+
+	// Whether or not a bracketed paste is in progress, and the pasted bytes.
+	pasting bool
+	paste   []byte
+
+	// The state of the mouse buttons (see tcell's tScreen.buildMouseEvent()).
+	buttonDown, wasButton bool
+}
+
+// prepare builds the key table for the given terminal.
+func (p *streamParser) prepare(ti *terminfo.Terminfo) {
+	p.keys = make(map[string]streamKey)
+	p.has = make(map[tcell.Key]bool)
+	add := func(key tcell.Key, mod tcell.ModMask, sequence string) {
+		if sequence == "" {
+			return
+		}
+		if _, ok := p.keys[sequence]; !ok {
+			p.keys[sequence] = streamKey{key: key, mod: mod}
+			p.has[key] = true
+		}
+	}
+
+	functionKeys := []string{ti.KeyF1, ti.KeyF2, ti.KeyF3, ti.KeyF4, ti.KeyF5,
+		ti.KeyF6, ti.KeyF7, ti.KeyF8, ti.KeyF9, ti.KeyF10, ti.KeyF11, ti.KeyF12,
+		ti.KeyF13, ti.KeyF14, ti.KeyF15, ti.KeyF16, ti.KeyF17, ti.KeyF18, ti.KeyF19,
+		ti.KeyF20, ti.KeyF21, ti.KeyF22, ti.KeyF23, ti.KeyF24}
+	for index, sequence := range functionKeys {
+		add(tcell.KeyF1+tcell.Key(index), tcell.ModNone, sequence)
+	}
+
+	add(tcell.KeyBackspace, tcell.ModNone, ti.KeyBackspace)
+	add(tcell.KeyInsert, tcell.ModNone, ti.KeyInsert)
+	add(tcell.KeyDelete, tcell.ModNone, ti.KeyDelete)
+	add(tcell.KeyHome, tcell.ModNone, ti.KeyHome)
+	add(tcell.KeyEnd, tcell.ModNone, ti.KeyEnd)
+	add(tcell.KeyUp, tcell.ModNone, ti.KeyUp)
+	add(tcell.KeyDown, tcell.ModNone, ti.KeyDown)
+	add(tcell.KeyLeft, tcell.ModNone, ti.KeyLeft)
+	add(tcell.KeyRight, tcell.ModNone, ti.KeyRight)
+	add(tcell.KeyPgUp, tcell.ModNone, ti.KeyPgUp)
+	add(tcell.KeyPgDn, tcell.ModNone, ti.KeyPgDn)
+	add(tcell.KeyHelp, tcell.ModNone, ti.KeyHelp)
+	add(tcell.KeyPrint, tcell.ModNone, ti.KeyPrint)
+	add(tcell.KeyCancel, tcell.ModNone, ti.KeyCancel)
+	add(tcell.KeyExit, tcell.ModNone, ti.KeyExit)
+	add(tcell.KeyBacktab, tcell.ModNone, ti.KeyBacktab)
+
+	modified := []struct {
+		mod                              tcell.ModMask
+		up, down, left, right, home, end string
+	}{
+		{tcell.ModShift, ti.KeyShfUp, ti.KeyShfDown, ti.KeyShfLeft, ti.KeyShfRight, ti.KeyShfHome, ti.KeyShfEnd},
+		{tcell.ModCtrl, ti.KeyCtrlUp, ti.KeyCtrlDown, ti.KeyCtrlLeft, ti.KeyCtrlRight, ti.KeyCtrlHome, ti.KeyCtrlEnd},
+		{tcell.ModAlt, ti.KeyAltUp, ti.KeyAltDown, ti.KeyAltLeft, ti.KeyAltRight, ti.KeyAltHome, ti.KeyAltEnd},
+		{tcell.ModAlt, ti.KeyMetaUp, ti.KeyMetaDown, ti.KeyMetaLeft, ti.KeyMetaRight, ti.KeyMetaHome, ti.KeyMetaEnd},
+		{tcell.ModAlt | tcell.ModShift, ti.KeyAltShfUp, ti.KeyAltShfDown, ti.KeyAltShfLeft, ti.KeyAltShfRight, ti.KeyAltShfHome, ti.KeyAltShfEnd},
+		{tcell.ModCtrl | tcell.ModShift, ti.KeyCtrlShfUp, ti.KeyCtrlShfDown, ti.KeyCtrlShfLeft, ti.KeyCtrlShfRight, ti.KeyCtrlShfHome, ti.KeyCtrlShfEnd},
+	}
+	for _, m := range modified {
+		add(tcell.KeyUp, m.mod, m.up)
+		add(tcell.KeyDown, m.mod, m.down)
+		add(tcell.KeyLeft, m.mod, m.left)
+		add(tcell.KeyRight, m.mod, m.right)
+		add(tcell.KeyHome, m.mod, m.home)
+		add(tcell.KeyEnd, m.mod, m.end)
+	}
+
+	// Terminals send different sequences depending on their mode, and many
+	// are missing from terminfo. Add the common ones.
+	add(tcell.KeyUp, tcell.ModNone, "\x1b[A")
+	add(tcell.KeyDown, tcell.ModNone, "\x1b[B")
+	add(tcell.KeyRight, tcell.ModNone, "\x1b[C")
+	add(tcell.KeyLeft, tcell.ModNone, "\x1b[D")
+	add(tcell.KeyEnd, tcell.ModNone, "\x1b[F")
+	add(tcell.KeyHome, tcell.ModNone, "\x1b[H")
+	add(tcell.KeyDelete, tcell.ModNone, "\x1b[3~")
+	add(tcell.KeyHome, tcell.ModNone, "\x1b[1~")
+	add(tcell.KeyEnd, tcell.ModNone, "\x1b[4~")
+	add(tcell.KeyPgUp, tcell.ModNone, "\x1b[5~")
+	add(tcell.KeyPgDn, tcell.ModNone, "\x1b[6~")
+	add(tcell.KeyUp, tcell.ModNone, "\x1bOA")
+	add(tcell.KeyDown, tcell.ModNone, "\x1bOB")
+	add(tcell.KeyRight, tcell.ModNone, "\x1bOC")
+	add(tcell.KeyLeft, tcell.ModNone, "\x1bOD")
+	add(tcell.KeyHome, tcell.ModNone, "\x1bOH")
+	add(tcell.KeyEnd, tcell.ModNone, "\x1bOF")
+	add(tcell.KeyBacktab, tcell.ModNone, "\x1b[Z")
+
+	// Control keys.
+	for key := tcell.Key(0); key < ' '; key++ {
+		mod := tcell.ModCtrl
+		switch key {
+		case tcell.KeyBS, tcell.KeyTAB, tcell.KeyESC, tcell.KeyCR:
+			mod = tcell.ModNone // Directly typeable.
+		}
+		add(key, mod, string(rune(key)))
+	}
+	add(tcell.KeyDEL, tcell.ModNone, "\x7f")
+
+	// Remember the prefixes to detect incomplete sequences. The escape key
+	// itself is handled separately.
+	delete(p.keys, "\x1b")
+	p.prefixes = make(map[string]bool)
+	for sequence := range p.keys {
+		for length := 1; length < len(sequence); length++ {
+			p.prefixes[sequence[:length]] = true
+		}
+		if len(sequence) > p.longest {
+			p.longest = len(sequence)
+		}
+	}
+}
+
+// parse decodes the buffered input. Input which may be the beginning of an
+// escape sequence remains buffered unless "expire" is true.
+func (p *streamParser) parse(expire bool, width, height int) (events []tcell.Event) {
+	for len(p.buffer) > 0 {
+		if p.pasting {
+			if !p.parsePaste(&events) {
+				break
+			}
+			continue
+		}
+
+		complete, partial := p.parseSequence(&events, width, height)
+		if complete {
+			continue
+		}
+		if partial && !expire {
+			break // Wait for more input.
+		}
+
+		if p.parseRune(&events) {
+			continue
+		}
+		if !expire && !utf8.FullRune(p.buffer) {
+			break
+		}
+
+		// Nothing matches. A lone escape key or the Alt prefix.
+		b := p.buffer[0]
+		p.buffer = p.buffer[1:]
+		if b == '\x1b' {
+			if len(p.buffer) == 0 {
+				events = append(events, tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+				p.escaped = false
+			} else {
+				p.escaped = true
+			}
+			continue
+		}
+		events = append(events, tcell.NewEventKey(tcell.KeyRune, rune(b), p.modifiers(tcell.ModNone)))
+	}
+
+	return
+}
+
+// modifiers adds the Alt modifier to the given modifiers if the previous byte
+// was an escape.
+func (p *streamParser) modifiers(mod tcell.ModMask) tcell.ModMask {
+	if p.escaped {
+		p.escaped = false
+		mod |= tcell.ModAlt
+	}
+	return mod
+}
+
+// parseRune decodes a printable character at the beginning of the buffer.
+func (p *streamParser) parseRune(events *[]tcell.Event) bool {
+	r, size := utf8.DecodeRune(p.buffer)
+	if r < ' ' || r == '\x7f' || r == utf8.RuneError {
+		return false
+	}
+	p.buffer = p.buffer[size:]
+	*events = append(*events, tcell.NewEventKey(tcell.KeyRune, r, p.modifiers(tcell.ModNone)))
+	return true
+}
+
+// parseSequence decodes an escape sequence or control character at the
+// beginning of the buffer. "partial" is true if the buffer may contain the
+// beginning of an escape sequence.
+func (p *streamParser) parseSequence(events *[]tcell.Event, width, height int) (complete, partial bool) {
+	b := p.buffer
+
+	// Bracketed paste.
+	const pasteStart = "\x1b[200~"
+	if bytes.HasPrefix(b, []byte(pasteStart)) {
+		p.buffer = b[len(pasteStart):]
+		p.pasting = true
+		p.paste = p.paste[:0]
+		return true, false
+	}
+	if bytes.HasPrefix([]byte(pasteStart), b) {
+		partial = true
+	}
+
+	// Focus reports.
+	for _, report := range []string{"\x1b[I", "\x1b[O"} {
+		if bytes.HasPrefix(b, []byte(report)) {
+			p.buffer = b[len(report):]
+			p.escaped = false
+			*events = append(*events, NewEventTerminalFocus(report[2] == 'I'))
+			return true, false
+		}
+		if bytes.HasPrefix([]byte(report), b) {
+			partial = true
+		}
+	}
+
+	// Mouse events.
+	if complete, part := p.parseSgrMouse(events, width, height); complete {
+		return true, false
+	} else if part {
+		partial = true
+	}
+	if complete, part := p.parseX10Mouse(events, width, height); complete {
+		return true, false
+	} else if part {
+		partial = true
+	}
+
+	// Keys, longest match first.
+	var (
+		match int
+		key   streamKey
+	)
+	for length := 1; length <= p.longest && length <= len(b); length++ {
+		if k, ok := p.keys[string(b[:length])]; ok {
+			match, key = length, k
+		}
+	}
+	if len(b) < p.longest && p.prefixes[string(b)] {
+		partial = true
+	}
+	if match > 0 && (!partial || match > 1) {
+		p.buffer = b[match:]
+		var r rune
+		if match == 1 {
+			r = rune(b[0])
+		}
+		*events = append(*events, tcell.NewEventKey(key.key, r, p.modifiers(key.mod)))
+		return true, false
+	}
+
+	return false, partial
+}
+
+// parsePaste collects pasted text until the end of a bracketed paste. Returns
+// false if more input is needed.
+func (p *streamParser) parsePaste(events *[]tcell.Event) bool {
+	const pasteEnd = "\x1b[201~"
+	if index := bytes.Index(p.buffer, []byte(pasteEnd)); index >= 0 {
+		p.paste = append(p.paste, p.buffer[:index]...)
+		p.buffer = p.buffer[index+len(pasteEnd):]
+		p.pasting = false
+
+		text := strings.Replace(string(p.paste), "\r\n", "\n", -1)
+		text = strings.Replace(text, "\r", "\n", -1)
+		*events = append(*events, NewEventPaste(text))
+		return true
+	}
+
+	// Keep what may be the beginning of the end marker.
+	keep := 0
+	for length := 1; length < len(pasteEnd) && length <= len(p.buffer); length++ {
+		if bytes.HasSuffix(p.buffer, []byte(pasteEnd[:length])) {
+			keep = length
+		}
+	}
+	p.paste = append(p.paste, p.buffer[:len(p.buffer)-keep]...)
+	p.buffer = p.buffer[len(p.buffer)-keep:]
+	return false
+}
+
+// parseSgrMouse decodes an SGR mouse report ("ESC [ < b ; x ; y M").
+func (p *streamParser) parseSgrMouse(events *[]tcell.Event, width, height int) (complete, partial bool) {
+	const prefix = "\x1b[<"
+	b := p.buffer
+	if len(b) < len(prefix) {
+		return false, strings.HasPrefix(prefix, string(b))
+	}
+	if !bytes.HasPrefix(b, []byte(prefix)) {
+		return false, false
+	}
+
+	var values [3]int
+	field := 0
+	for index := len(prefix); index < len(b); index++ {
+		switch c := b[index]; {
+		case c >= '0' && c <= '9':
+			values[field] = values[field]*10 + int(c-'0')
+		case c == ';':
+			if field++; field > 2 {
+				return false, false
+			}
+		case (c == 'M' || c == 'm') && field == 2:
+			button, motion := values[0], values[0]&32 != 0
+			button &^= 32
+			if c == 'm' {
+				// Release.
+				button |= 3
+				button &^= 0x40
+				p.buttonDown = false
+			} else if motion {
+				if !p.buttonDown {
+					button |= 3
+					button &^= 0x40
+				}
+			} else {
+				p.buttonDown = true
+			}
+			p.buffer = b[index+1:]
+			*events = append(*events, p.mouseEvent(values[1]-1, values[2]-1, button, width, height))
+			return true, false
+		default:
+			return false, false
+		}
+	}
+
+	return false, true
+}
+
+// parseX10Mouse decodes a legacy mouse report ("ESC [ M b x y").
+func (p *streamParser) parseX10Mouse(events *[]tcell.Event, width, height int) (complete, partial bool) {
+	const prefix = "\x1b[M"
+	b := p.buffer
+	if len(b) < len(prefix) {
+		return false, strings.HasPrefix(prefix, string(b))
+	}
+	if !bytes.HasPrefix(b, []byte(prefix)) {
+		return false, false
+	}
+	if len(b) < len(prefix)+3 {
+		return false, true
+	}
+
+	button := int(b[3]) - 32
+	x, y := int(b[4])-32-1, int(b[5])-32-1
+	p.buffer = b[6:]
+	*events = append(*events, p.mouseEvent(x, y, button, width, height))
+	return true, false
+}
+
+// mouseEvent returns the mouse event for the given xterm button code.
+func (p *streamParser) mouseEvent(x, y, code, width, height int) *tcell.EventMouse {
+	button := tcell.ButtonNone
+	switch code & 0x43 {
+	case 0:
+		button, p.wasButton = tcell.Button1, true
+	case 1:
+		button, p.wasButton = tcell.Button2, true
+	case 2:
+		button, p.wasButton = tcell.Button3, true
+	case 3:
+		p.wasButton = false
+	case 0x40:
+		button = tcell.WheelUp
+		if p.wasButton {
+			button = tcell.Button1
+		}
+	case 0x41:
+		button = tcell.WheelDown
+		if p.wasButton {
+			button = tcell.Button2
+		}
+	}
+
+	mod := tcell.ModNone
+	if code&0x4 != 0 {
+		mod |= tcell.ModShift
+	}
+	if code&0x8 != 0 {
+		mod |= tcell.ModAlt
+	}
+	if code&0x10 != 0 {
+		mod |= tcell.ModCtrl
+	}
+
+	// Some terminals report positions outside the screen during drags.
+	if x >= width {
+		x = width - 1
+	}
+	if y >= height {
+		y = height - 1
+	}
+	if x < 0 {
+		x = 0
+	}
+	if y < 0 {
+		y = 0
+	}
+
+	return tcell.NewEventMouse(x, y, button, mod)
+}
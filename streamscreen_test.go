@@ -0,0 +1,214 @@
+package tview
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/tcell"
+	"github.com/diamondburned/tcell/terminfo"
+)
+
+// testStream is the terminal end of a StreamScreen. Input is written to the
+// pipe, output is recorded.
+type testStream struct {
+	*io.PipeReader
+	input *io.PipeWriter
+
+	sync.Mutex
+	output bytes.Buffer
+}
+
+func (s *testStream) Write(p []byte) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+	return s.output.Write(p)
+}
+
+// takeOutput returns the output written so far and discards it.
+func (s *testStream) takeOutput() string {
+	s.Lock()
+	defer s.Unlock()
+	output := s.output.String()
+	s.output.Reset()
+	return output
+}
+
+// newTestStreamScreen returns an initialized xterm stream screen and its
+// terminal end.
+func newTestStreamScreen(t *testing.T) (*StreamScreen, *testStream) {
+	t.Helper()
+	stream := &testStream{}
+	stream.PipeReader, stream.input = io.Pipe()
+	screen, err := NewStreamScreen(stream, "xterm-256color")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	return screen, stream
+}
+
+// describeEvent returns a short description of the given event.
+func describeEvent(event tcell.Event) string {
+	switch event := event.(type) {
+	case *tcell.EventKey:
+		return event.Name()
+	case *tcell.EventMouse:
+		x, y := event.Position()
+		return fmt.Sprintf("Mouse[%d,%d,%d,%d]", x, y, event.Buttons(), event.Modifiers())
+	case *EventPaste:
+		return fmt.Sprintf("Paste[%q]", event.Text())
+	case *tcell.EventResize:
+		width, height := event.Size()
+		return fmt.Sprintf("Resize[%d,%d]", width, height)
+	case *EventTerminalFocus:
+		return fmt.Sprintf("Focus[%t]", event.Focused())
+	case *streamEndEvent:
+		return "End"
+	}
+	return fmt.Sprintf("%T", event)
+}
+
+// pollEvents returns the descriptions of the given number of events.
+func pollEvents(t *testing.T, screen *StreamScreen, count int) (events []string) {
+	t.Helper()
+	for len(events) < count {
+		polled := make(chan tcell.Event, 1)
+		go func() {
+			polled <- screen.PollEvent()
+		}()
+		select {
+		case event := <-polled:
+			events = append(events, describeEvent(event))
+		case <-time.After(time.Second):
+			t.Fatalf("received %v, expected %d events", events, count)
+		}
+	}
+	return
+}
+
+func TestStreamScreenInitFini(t *testing.T) {
+	ti, err := terminfo.LookupTerminfo("xterm-256color")
+	if err != nil {
+		t.Fatal(err)
+	}
+	screen, stream := newTestStreamScreen(t)
+	defer stream.input.Close()
+
+	output := stream.takeOutput()
+	for _, sequence := range []string{ti.EnterCA, ti.HideCursor, ti.EnterKeypad, ti.EnableAcs, ti.Clear} {
+		if !strings.Contains(output, sequence) {
+			t.Errorf("Init() output %q lacks %q", output, sequence)
+		}
+	}
+
+	screen.SetContent(1, 0, 'x', nil, tcell.StyleDefault)
+	screen.Show()
+	if output := stream.takeOutput(); !strings.Contains(output, "x") {
+		t.Errorf("Show() output %q lacks the new cell", output)
+	}
+
+	screen.EnableMouse()
+	stream.takeOutput()
+	screen.Fini()
+	output = stream.takeOutput()
+	for _, sequence := range []string{ti.ShowCursor, ti.ExitCA, ti.ExitKeypad, "\x1b[?1000l"} {
+		if !strings.Contains(output, sequence) {
+			t.Errorf("Fini() output %q lacks %q", output, sequence)
+		}
+	}
+	if event := screen.PollEvent(); event != nil {
+		t.Errorf("PollEvent() returned %s after Fini()", describeEvent(event))
+	}
+}
+
+func TestStreamScreenInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []string
+		events []string
+	}{
+		{"runes", []string{"aä€"}, []string{"Rune[a]", "Rune[ä]", "Rune[€]"}},
+		{"split rune", []string{"\xe2\x82", "\xac"}, []string{"Rune[€]"}},
+		{"control keys", []string{"\x01\r\t\x7f"}, []string{"Ctrl+A", "Enter", "Tab", "Backspace2"}},
+		{"cursor keys", []string{"\x1b[A\x1bOB\x1b[1;5C"}, []string{"Up", "Down", "Ctrl+Right"}},
+		{"function keys", []string{"\x1bOP\x1b[15~"}, []string{"F1", "F5"}},
+		{"split sequence", []string{"\x1b[", "3~"}, []string{"Delete"}},
+		{"alt", []string{"\x1bx"}, []string{"Alt+Rune[x]"}},
+		{"escape", []string{"\x1b"}, []string{"Esc"}},
+		{"unknown sequence", []string{"\x1b[99"}, []string{"Alt+Rune[[]", "Rune[9]", "Rune[9]"}},
+		{"focus", []string{"\x1b[I\x1b[O"}, []string{"Focus[true]", "Focus[false]"}},
+		{"sgr mouse", []string{"\x1b[<0;5;3M\x1b[<32;6;3M\x1b[<0;6;3m"}, []string{"Mouse[4,2,1,0]", "Mouse[5,2,1,0]", "Mouse[5,2,0,0]"}},
+		{"sgr wheel", []string{"\x1b[<64;1;1M\x1b[<65;1;1M"}, []string{"Mouse[0,0,256,0]", "Mouse[0,0,512,0]"}},
+		{"sgr modifiers", []string{"\x1b[<16;1;1M"}, []string{"Mouse[0,0,1,2]"}},
+		{"sgr outside", []string{"\x1b[<0;200;100M"}, []string{"Mouse[79,23,1,0]"}},
+		{"split mouse", []string{"\x1b[<0;1", "0;2M"}, []string{"Mouse[9,1,1,0]"}},
+		{"x10 mouse", []string{"\x1b[M\x20\x22\x23\x1b[M\x23\x22\x23"}, []string{"Mouse[1,2,1,0]", "Mouse[1,2,0,0]"}},
+		{"paste", []string{"\x1b[200~one\r\ntwo\x1b[201~x"}, []string{`Paste["one\ntwo"]`, "Rune[x]"}},
+		{"split paste", []string{"\x1b[20", "0~a\x1b[A", "b\x1b[20", "1~"}, []string{`Paste["a\x1b[Ab"]`}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			screen, stream := newTestStreamScreen(t)
+			defer stream.input.Close()
+			for _, input := range test.input {
+				stream.input.Write([]byte(input))
+				time.Sleep(time.Millisecond)
+			}
+			events := pollEvents(t, screen, len(test.events))
+			if strings.Join(events, " ") != strings.Join(test.events, " ") {
+				t.Errorf("input %q produced %v, expected %v", test.input, events, test.events)
+			}
+		})
+	}
+}
+
+func TestStreamScreenResize(t *testing.T) {
+	screen, stream := newTestStreamScreen(t)
+	defer stream.input.Close()
+
+	// Resizing doesn't block, even if the event queue is full.
+	for width := 100; width < 120; width++ {
+		screen.SetSize(width, 30)
+	}
+	if width, height := screen.Size(); width != 119 || height != 30 {
+		t.Errorf("size is %dx%d, expected 119x30", width, height)
+	}
+	events := strings.Join(pollEvents(t, screen, 20), " ")
+	if !strings.HasPrefix(events, "Resize[100,30]") || !strings.Contains(events, "Resize[119,30]") {
+		t.Errorf("unexpected resize events %s", events)
+	}
+
+	// The whole terminal is redrawn at the new size.
+	stream.takeOutput()
+	screen.SetContent(118, 29, 'x', nil, tcell.StyleDefault)
+	screen.Show()
+	if output := stream.takeOutput(); !strings.Contains(output, "\x1b[30;1H") || !strings.HasSuffix(output, strings.Repeat(" ", 118)+"x") {
+		t.Errorf("output %q doesn't draw the last cell", output)
+	}
+}
+
+func TestStreamScreenEnd(t *testing.T) {
+	screen, stream := newTestStreamScreen(t)
+
+	// The input loop terminates when the stream ends, even if the
+	// application has stopped reading events.
+	stream.input.Write(bytes.Repeat([]byte("a"), 20))
+	screen.Fini()
+	stream.input.Close()
+	select {
+	case <-screen.done:
+	case <-time.After(time.Second):
+		t.Fatal("input loop did not terminate")
+	}
+	if err := screen.Init(); err != ErrStreamClosed {
+		t.Errorf("Init() returned %v after the stream ended", err)
+	}
+}
@@ -2,6 +2,7 @@ package tview
 
 import (
 	"sort"
+	"strings"
 
 	"github.com/diamondburned/tcell"
 	colorful "github.com/diamondburned/go-colorful"
@@ -247,6 +248,10 @@ type Table struct {
 	// The number of visible rows the last time the table was drawn.
 	visibleRows int
 
+	// The indices of the rows and columns and the widths of the columns which
+	// were visible the last time the table was drawn.
+	drawnRows, drawnColumns, drawnWidths []int
+
 	// The style of the selected rows. If this value is 0, selected rows are
 	// simply inverted.
 	selectedStyle tcell.Style
@@ -738,6 +743,9 @@ ColumnLoop:
 		}
 	}
 
+	// Remember what we draw for mouse events.
+	t.drawnRows, t.drawnColumns, t.drawnWidths = rows, columns, widths
+
 	// Helper function which draws border runes.
 	borderStyle := tcell.StyleDefault.Background(t.backgroundColor).Foreground(t.bordersColor)
 	drawBorder := func(colX, rowY int, ch rune) {
@@ -935,6 +943,13 @@ ColumnLoop:
 	}
 }
 
+// ConsumesKey returns whether or not the table uses the given key itself. This
+// is the case for Tab and Backtab if a handler was installed with
+// SetDoneFunc().
+func (t *Table) ConsumesKey(event *tcell.EventKey) bool {
+	return isTabKey(event) && t.done != nil
+}
+
 // InputHandler returns the handler for this primitive.
 func (t *Table) InputHandler() func(event *tcell.EventKey, setFocus func(p Primitive)) {
 	return t.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p Primitive)) {
@@ -953,50 +968,8 @@ func (t *Table) InputHandler() func(event *tcell.EventKey, setFocus func(p Primi
 		// Movement functions.
 		previouslySelectedRow, previouslySelectedColumn := t.selectedRow, t.selectedColumn
 		var (
-			getCell = func(row, column int) *TableCell {
-				if row < 0 || column < 0 || row >= len(t.cells) || column >= len(t.cells[row]) {
-					return nil
-				}
-				return t.cells[row][column]
-			}
-
-			previous = func() {
-				for t.selectedRow >= 0 {
-					cell := getCell(t.selectedRow, t.selectedColumn)
-					if cell == nil || !cell.NotSelectable {
-						return
-					}
-					t.selectedColumn--
-					if t.selectedColumn < 0 {
-						t.selectedColumn = t.lastColumn
-						t.selectedRow--
-					}
-				}
-			}
-
-			next = func() {
-				if t.selectedColumn > t.lastColumn {
-					t.selectedColumn = 0
-					t.selectedRow++
-					if t.selectedRow >= len(t.cells) {
-						t.selectedRow = len(t.cells) - 1
-					}
-				}
-				for t.selectedRow < len(t.cells) {
-					cell := getCell(t.selectedRow, t.selectedColumn)
-					if cell == nil || !cell.NotSelectable {
-						return
-					}
-					t.selectedColumn++
-					if t.selectedColumn > t.lastColumn {
-						t.selectedColumn = 0
-						t.selectedRow++
-					}
-				}
-				t.selectedColumn = t.lastColumn
-				t.selectedRow = len(t.cells) - 1
-				previous()
-			}
+			previous = t.previousSelectable
+			next     = t.nextSelectable
 
 			home = func() {
 				if t.rowsSelectable {
@@ -1021,30 +994,8 @@ func (t *Table) InputHandler() func(event *tcell.EventKey, setFocus func(p Primi
 				}
 			}
 
-			down = func() {
-				if t.rowsSelectable {
-					t.selectedRow++
-					if t.selectedRow >= len(t.cells) {
-						t.selectedRow = len(t.cells) - 1
-					}
-					next()
-				} else {
-					t.rowOffset++
-				}
-			}
-
-			up = func() {
-				if t.rowsSelectable {
-					t.selectedRow--
-					if t.selectedRow < 0 {
-						t.selectedRow = 0
-					}
-					previous()
-				} else {
-					t.trackEnd = false
-					t.rowOffset--
-				}
-			}
+			down = t.moveDown
+			up   = t.moveUp
 
 			left = func() {
 				if t.columnsSelectable {
@@ -1142,3 +1093,194 @@ func (t *Table) InputHandler() func(event *tcell.EventKey, setFocus func(p Primi
 		}
 	})
 }
+
+// selectableCell returns the cell at the given position or nil if there is
+// none.
+func (t *Table) selectableCell(row, column int) *TableCell {
+	if row < 0 || column < 0 || row >= len(t.cells) || column >= len(t.cells[row]) {
+		return nil
+	}
+	return t.cells[row][column]
+}
+
+// previousSelectable moves the selection backwards, starting at the selected
+// cell, until it is on a cell which is not marked as NotSelectable.
+func (t *Table) previousSelectable() {
+	for t.selectedRow >= 0 {
+		cell := t.selectableCell(t.selectedRow, t.selectedColumn)
+		if cell == nil || !cell.NotSelectable {
+			return
+		}
+		t.selectedColumn--
+		if t.selectedColumn < 0 {
+			t.selectedColumn = t.lastColumn
+			t.selectedRow--
+		}
+	}
+}
+
+// nextSelectable moves the selection forward, starting at the selected cell,
+// until it is on a cell which is not marked as NotSelectable.
+func (t *Table) nextSelectable() {
+	if t.selectedColumn > t.lastColumn {
+		t.selectedColumn = 0
+		t.selectedRow++
+		if t.selectedRow >= len(t.cells) {
+			t.selectedRow = len(t.cells) - 1
+		}
+	}
+	for t.selectedRow < len(t.cells) {
+		cell := t.selectableCell(t.selectedRow, t.selectedColumn)
+		if cell == nil || !cell.NotSelectable {
+			return
+		}
+		t.selectedColumn++
+		if t.selectedColumn > t.lastColumn {
+			t.selectedColumn = 0
+			t.selectedRow++
+		}
+	}
+	t.selectedColumn = t.lastColumn
+	t.selectedRow = len(t.cells) - 1
+	t.previousSelectable()
+}
+
+// moveDown moves the selection down by one row or, if rows are not
+// selectable, scrolls the table down.
+func (t *Table) moveDown() {
+	if t.rowsSelectable {
+		t.selectedRow++
+		if t.selectedRow >= len(t.cells) {
+			t.selectedRow = len(t.cells) - 1
+		}
+		t.nextSelectable()
+	} else {
+		t.rowOffset++
+	}
+}
+
+// moveUp moves the selection up by one row or, if rows are not selectable,
+// scrolls the table up.
+func (t *Table) moveUp() {
+	if t.rowsSelectable {
+		t.selectedRow--
+		if t.selectedRow < 0 {
+			t.selectedRow = 0
+		}
+		t.previousSelectable()
+	} else {
+		t.trackEnd = false
+		t.rowOffset--
+	}
+}
+
+// cellAt returns the row and column of the cell shown at the given screen
+// coordinates. If there is no cell at that position, -1 is returned for both.
+func (t *Table) cellAt(x, y int) (row, column int) {
+	if !t.InInnerRect(x, y) {
+		return -1, -1
+	}
+	rectX, rectY, _, _ := t.GetInnerRect()
+
+	// Find the row.
+	rowY := y - rectY
+	if t.borders {
+		if rowY%2 == 0 {
+			return -1, -1 // A horizontal border.
+		}
+		rowY /= 2
+	}
+	if rowY >= len(t.drawnRows) {
+		return -1, -1
+	}
+
+	// Find the column. Each column starts with its separator or border.
+	columnX := rectX
+	if !t.borders {
+		columnX--
+	}
+	for index, width := range t.drawnWidths {
+		if x > columnX && x <= columnX+width {
+			return t.drawnRows[rowY], t.drawnColumns[index]
+		}
+		columnX += width + 1
+	}
+
+	return -1, -1
+}
+
+// MouseHandler returns the mouse handler for this primitive. Clicking a cell
+// selects it, double-clicking it also triggers the "selected" callback. The
+// mouse wheel moves the selection or, if nothing is selectable, scrolls the
+// table.
+func (t *Table) MouseHandler() func(event *MouseEvent) bool {
+	return func(event *MouseEvent) bool {
+		previouslySelectedRow, previouslySelectedColumn := t.selectedRow, t.selectedColumn
+
+		switch event.Action {
+		case MouseScrollUp:
+			t.moveUp()
+		case MouseScrollDown:
+			t.moveDown()
+		case MouseClick, MouseDoubleClick:
+			if event.Button != tcell.Button1 || !t.rowsSelectable && !t.columnsSelectable {
+				return false
+			}
+			row, column := t.cellAt(event.Position())
+			if row < 0 {
+				return false
+			}
+			if cell := t.GetCell(row, column); cell != nil && cell.NotSelectable {
+				return false
+			}
+			t.selectedRow, t.selectedColumn = row, column
+			if event.Action == MouseDoubleClick && t.selected != nil {
+				t.selected(t.selectedRow, t.selectedColumn)
+			}
+		default:
+			return false
+		}
+
+		// If the selection has changed, notify the handler.
+		if t.selectionChanged != nil &&
+			(t.rowsSelectable && previouslySelectedRow != t.selectedRow ||
+				t.columnsSelectable && previouslySelectedColumn != t.selectedColumn) {
+			t.selectionChanged(t.selectedRow, t.selectedColumn)
+		}
+
+		return true
+	}
+}
+
+// CopyHandler returns the handler which provides the selection of this table
+// for copying to the clipboard. Depending on what is selectable (see
+// SetSelectable()), this is the text of the selected cell, of the selected row
+// with cells separated by tabs, or of the selected column with cells separated
+// by line breaks. Color tags are removed.
+func (t *Table) CopyHandler() func() string {
+	return func() string {
+		cellText := func(row, column int) string {
+			cell := t.GetCell(row, column)
+			_, _, _, _, _, text, _ := decomposeString(cell.Text, true, false)
+			return text
+		}
+
+		switch {
+		case t.rowsSelectable && t.columnsSelectable:
+			return cellText(t.selectedRow, t.selectedColumn)
+		case t.rowsSelectable:
+			cells := make([]string, t.GetColumnCount())
+			for column := range cells {
+				cells[column] = cellText(t.selectedRow, column)
+			}
+			return strings.TrimRight(strings.Join(cells, "\t"), "\t")
+		case t.columnsSelectable:
+			cells := make([]string, t.GetRowCount())
+			for row := range cells {
+				cells[row] = cellText(row, t.selectedColumn)
+			}
+			return strings.Join(cells, "\n")
+		}
+		return ""
+	}
+}
@@ -0,0 +1,181 @@
+package tview
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/tcell"
+)
+
+// The escape sequences which enable and disable focus reporting, i.e. ask the
+// terminal to send "ESC [ I" when its window receives focus and "ESC [ O" when
+// it loses focus.
+const (
+	focusReportingEnable  = "\x1b[?1004h"
+	focusReportingDisable = "\x1b[?1004l"
+)
+
+// The escape sequences which save the terminal's window title on the
+// terminal's title stack, restore it, and set a new title (OSC 0, which sets
+// both the window title and the icon or tab title).
+const (
+	titlePush = "\x1b[22;0t"
+	titlePop  = "\x1b[23;0t"
+	titleSet  = "\x1b]0;%s\x07"
+)
+
+// EventTerminalFocus is an event which indicates that the terminal window
+// running the application received or lost focus. It is delivered to the
+// function installed with Application.SetTerminalFocusFunc(). Terminals which
+// don't support focus reporting never send it.
+type EventTerminalFocus struct {
+	t       time.Time
+	focused bool
+}
+
+// NewEventTerminalFocus returns a new terminal focus event. It may be sent to
+// the application with QueueEvent() to simulate a change of focus.
+func NewEventTerminalFocus(focused bool) *EventTerminalFocus {
+	return &EventTerminalFocus{t: time.Now(), focused: focused}
+}
+
+// When returns the time when the event was received.
+func (e *EventTerminalFocus) When() time.Time {
+	return e.t
+}
+
+// Focused returns true if the terminal received focus and false if it lost
+// it.
+func (e *EventTerminalFocus) Focused() bool {
+	return e.focused
+}
+
+// SetTerminalFocusFunc installs a callback function which is invoked when the
+// terminal window running the application receives or loses focus, e.g. to
+// pause animations while the user is looking at a different window. The
+// application asks the terminal to report focus changes if the screen supports
+// sending escape sequences to the terminal. Not all terminals support this.
+//
+// Provide nil to uninstall the callback function.
+func (a *Application) SetTerminalFocusFunc(handler func(focused bool)) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.terminalFocus = handler
+}
+
+// GetTerminalFocusFunc returns the callback function installed with
+// SetTerminalFocusFunc() or nil if none has been installed.
+func (a *Application) GetTerminalFocusFunc() func(focused bool) {
+	a.RLock()
+	defer a.RUnlock()
+
+	return a.terminalFocus
+}
+
+// HasTerminalFocus returns false if the terminal reported that its window lost
+// focus and true otherwise.
+func (a *Application) HasTerminalFocus() bool {
+	a.RLock()
+	defer a.RUnlock()
+
+	return !a.terminalBlurred
+}
+
+// SetTitle sets the title of the terminal window (or tab) running the
+// application. The previous title is restored when the application stops or is
+// suspended. Provide an empty string to restore the previous title right away.
+// The title may be set before Run() is called. Control characters are removed
+// from the title. Nothing happens if the screen doesn't support sending escape
+// sequences to the terminal.
+func (a *Application) SetTitle(title string) {
+	title = strings.Map(func(r rune) rune {
+		if r < ' ' || r == '\x7f' || r >= '\x80' && r < '\xa0' {
+			return -1
+		}
+		return r
+	}, title)
+
+	a.Lock()
+	defer a.Unlock()
+
+	a.title = title
+	if a.Screen != nil && a.escapeCapable {
+		a.applyTitle(a.Screen)
+	}
+}
+
+// GetTitle returns the title set with SetTitle().
+func (a *Application) GetTitle() string {
+	a.RLock()
+	defer a.RUnlock()
+
+	return a.title
+}
+
+// handleTerminalFocus processes a terminal focus event.
+func (a *Application) handleTerminalFocus(event *EventTerminalFocus) {
+	a.Lock()
+	changed := a.terminalBlurred == event.Focused()
+	a.terminalBlurred = !event.Focused()
+	handler := a.terminalFocus
+	a.Unlock()
+
+	if changed && handler != nil {
+		handler(event.Focused())
+		a.Draw()
+	}
+}
+
+// enableTerminalModes asks the terminal to bracket pasted text, to report focus
+// changes, and to show the application's title, if the screen supports sending
+// escape sequences to the terminal. The application must be locked.
+func (a *Application) enableTerminalModes(screen tcell.Screen) {
+	terminal, ok := screen.(interface{ TPuts(string) })
+	a.escapeCapable = ok
+	if !ok {
+		return
+	}
+	a.bracketedPaste = setPasteMode(screen, true)
+	terminal.TPuts(focusReportingEnable)
+	a.applyTitle(screen)
+}
+
+// disableTerminalModes reverts what enableTerminalModes() did, restoring the
+// terminal's previous title. The application must be locked.
+func (a *Application) disableTerminalModes(screen tcell.Screen) {
+	terminal, ok := screen.(interface{ TPuts(string) })
+	if !ok {
+		return
+	}
+	a.bracketedPaste, a.escapeCapable = false, false
+	setPasteMode(screen, false)
+	terminal.TPuts(focusReportingDisable)
+	if a.titlePushed {
+		terminal.TPuts(titlePop)
+		a.titlePushed = false
+	}
+}
+
+// applyTitle sends the application's title to the terminal, saving the
+// terminal's title first. If the application's title is empty, the terminal's
+// title is restored. The application must be locked.
+func (a *Application) applyTitle(screen tcell.Screen) {
+	terminal, ok := screen.(interface{ TPuts(string) })
+	if !ok {
+		return
+	}
+	if a.title == "" {
+		if a.titlePushed {
+			terminal.TPuts(titlePop)
+			a.titlePushed = false
+		}
+		return
+	}
+	if !a.titlePushed {
+		terminal.TPuts(titlePush)
+		a.titlePushed = true
+	}
+	terminal.TPuts(fmt.Sprintf(titleSet, a.title))
+}
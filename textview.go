@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"unicode/utf8"
@@ -28,17 +29,43 @@ type textViewIndex struct {
 	Line            int    // The index into the "buffer" variable.
 	Pos             int    // The index into the "buffer" string (byte position).
 	NextPos         int    // The (byte) index of the next character in this buffer line.
+	StrippedPos     int    // The (byte) index into the buffer string with all tags removed.
 	Width           int    // The screen width of this line.
 	ForegroundColor string // The starting foreground color ("" = don't change, "-" = reset).
 	BackgroundColor string // The starting background color ("" = don't change, "-" = reset).
 	Attributes      string // The starting attributes ("" = don't change, "-" = reset).
 	Region          string // The starting region ID.
+	URL             string // The starting hyperlink target ("" = none).
+	Stale           bool   // Whether the buffer line needs to be wrapped again, in which case this line stands for all of it.
+}
+
+// textViewPosition is a position in the text of a text view.
+type textViewPosition struct {
+	Line int // The buffer line, like textViewIndex.Line.
+	Pos  int // The (byte) index into the buffer line with all tags removed.
+}
+
+// before returns true if this position comes before the given position.
+func (p textViewPosition) before(q textViewPosition) bool {
+	return p.Line < q.Line || p.Line == q.Line && p.Pos < q.Pos
+}
+
+// textViewRegion is an area of the screen which showed a region the last time
+// the text view was drawn.
+type textViewRegion struct {
+	X, Y, Width int    // The screen position and width.
+	ID          string // The region ID.
 }
 
 // TextView is a box which displays text. It implements the io.Writer interface
 // so you can stream text to it. This does not trigger a redraw automatically
 // but if a handler is installed via SetChangedFunc(), you can cause it to be
-// redrawn. (See SetChangedFunc() for more details.)
+// redrawn. (See SetChangedFunc() for more details.) To keep the memory used by
+// text which is written continuously, e.g. a log, within bounds, limit the
+// number of lines or bytes kept with SetMaxLines() or SetMaxBytes(). Writing
+// text which is appended to the end of the buffer only processes the new lines,
+// and when the text view is resized, only the visible lines are wrapped again
+// right away, so large buffers remain fast.
 //
 // Navigation
 //
@@ -53,6 +80,8 @@ type textViewIndex struct {
 //   - G, end: Move to the bottom.
 //   - Ctrl-F, page down: Move down by one page.
 //   - Ctrl-B, page up: Move up by one page.
+//   - /, ?: Search forward or backwards (if enabled with SetSearchPrompt()).
+//   - n, N: Move to the next or previous match of the search.
 //
 // If the text is not scrollable, any text above the top visible line is
 // discarded.
@@ -64,7 +93,7 @@ type textViewIndex struct {
 // If dynamic colors are enabled via SetDynamicColors(), text color can be
 // changed dynamically by embedding color strings in square brackets. This works
 // the same way as anywhere else. Please see the package documentation for more
-// information.
+// information. With SetHyperlinks(), color tags may also contain hyperlinks.
 //
 // Regions and Highlights
 //
@@ -87,6 +116,24 @@ type textViewIndex struct {
 // The ScrollToHighlight() function can be used to jump to the currently
 // highlighted region once when the text view is drawn the next time.
 //
+// Regions become links when a handler is installed with
+// SetRegionClickedFunc(): It is called when a region is clicked or when it is
+// highlighted with Tab or Backtab and Enter is pressed.
+//
+// Search
+//
+// SetSearch() highlights all matches of a string or regular expression in the
+// text, ignoring any tags. SearchNext() and SearchPrevious() scroll to the
+// matches one by one.
+//
+// Selection
+//
+// If enabled with SetSelectable(), text can be selected by dragging the mouse
+// over it and, if the text view is scrollable, by pressing the arrow keys while
+// holding Shift. Selected text is highlighted. GetSelection() returns it
+// without any tags. A function installed with SetCopyFunc() receives it when
+// the mouse button is released, e.g. to copy it to the clipboard.
+//
 // See https://github.com/rivo/tview/wiki/TextView for an example.
 type TextView struct {
 	sync.Mutex
@@ -95,6 +142,10 @@ type TextView struct {
 	// The text buffer.
 	buffer []string
 
+	// The maximum number of lines and bytes the buffer may hold (0 for no
+	// limit), and the number of bytes it currently holds.
+	maxLines, maxBytes, bufferBytes int
+
 	// The last bytes that have been received but are not part of the buffer yet.
 	recentBytes []byte
 
@@ -102,17 +153,19 @@ type TextView struct {
 	// to be re-indexed.
 	index []*textViewIndex
 
-	// The text alignment, one of AlignLeft, AlignCenter, or AlignRight.
-	align int
+	// The value of textViewIndex.Line for the first line of the buffer, which
+	// increases when lines are removed from the start of the buffer, and the
+	// number of buffer lines which are indexed. Lines after them were added or
+	// changed since the index was built.
+	indexBase, indexedLines int
 
-	// Indices into the "index" slice which correspond to the first line of the
-	// first highlight and the last line of the last highlight. This is calculated
-	// during re-indexing. Set to -1 if there is no current highlight.
-	fromHighlight, toHighlight int
+	// The ID of the region in which the first buffer line starts because its
+	// region tag was in a line which was removed (see trim()), or an empty
+	// string.
+	firstRegion string
 
-	// The screen space column of the highlight in its first line. Set to -1 if
-	// there is no current highlight.
-	posHighlight int
+	// The text alignment, one of AlignLeft, AlignCenter, or AlignRight.
+	align int
 
 	// A set of region IDs that are currently highlighted.
 	highlights map[string]struct{}
@@ -158,6 +211,9 @@ type TextView struct {
 	// If set to true, region tags can be used to define regions.
 	regions bool
 
+	// If set to true, color tags may contain hyperlinks.
+	hyperlinks bool
+
 	// A temporary flag which, when true, will automatically bring the current
 	// highlight(s) into the visible screen.
 	scrollToHighlights bool
@@ -169,6 +225,58 @@ type TextView struct {
 	// An optional function which is called when the user presses one of the
 	// following keys: Escape, Enter, Tab, Backtab.
 	done func(tcell.Key)
+
+	// An optional function which is called when lines were removed from the
+	// buffer to stay within its limits.
+	trimmed func(lines int)
+
+	// The search pattern as provided to SetSearch() and its compiled regular
+	// expression, nil if there is no search.
+	searchPattern string
+	search        *regexp.Regexp
+
+	// The current match: The buffer line (like textViewIndex.Line, -1 if there
+	// is no current match) and its start and end positions in the line with
+	// all tags removed.
+	searchLine, searchFrom, searchTo int
+
+	// The matches of the search in the buffer lines shown the last time the
+	// text view was drawn, by textViewIndex.Line, so they are not searched
+	// again in every frame.
+	searchMatches map[int][][]int
+
+	// A temporary flag which, when true, will bring the current match into
+	// the visible screen.
+	scrollToMatch bool
+
+	// If set to true, the "/" and "?" keys open a search prompt.
+	searchPrompt bool
+
+	// The open search prompt or nil if it is not open, and whether or not the
+	// last search through the prompt went backwards.
+	searchField     *InputField
+	searchBackwards bool
+
+	// If set to true, the user can select text.
+	selectable bool
+
+	// Whether or not text is selected, and the positions of the first and the
+	// last selected character, in any order. Both characters are selected.
+	selected                         bool
+	selectionAnchor, selectionCursor textViewPosition
+
+	// An optional function which is called with the selected text when the
+	// user has selected text with the mouse.
+	copied func(text string)
+
+	// An optional function which is called when the user clicks on a region
+	// or activates it with the Enter key.
+	regionClicked func(regionID string, event *MouseEvent)
+
+	// The region under the mouse pointer and the regions shown the last time
+	// the text view was drawn.
+	hoverRegion  string
+	drawnRegions []textViewRegion
 }
 
 // NewTextView returns a new text view.
@@ -177,6 +285,7 @@ func NewTextView() *TextView {
 		Box:           NewBox(),
 		highlights:    make(map[string]struct{}),
 		lineOffset:    -1,
+		searchLine:    -1,
 		scrollable:    true,
 		align:         AlignLeft,
 		wrap:          true,
@@ -264,7 +373,7 @@ func (t *TextView) GetText(stripTags bool) string {
 			text = regionPattern.ReplaceAllString(text, "")
 		}
 		if t.dynamicColors {
-			text = colorPattern.ReplaceAllString(text, "")
+			text = t.tagPattern().ReplaceAllString(text, "")
 		}
 		if t.regions || t.dynamicColors {
 			text = escapePattern.ReplaceAllString(text, `[$1$2]`)
@@ -284,6 +393,44 @@ func (t *TextView) SetDynamicColors(dynamic bool) *TextView {
 	return t
 }
 
+// SetHyperlinks sets the flag that allows color tags to contain a fourth field
+// with the target of a hyperlink if dynamic colors are enabled (see
+// SetDynamicColors()):
+//
+//   [<foreground>:<background>:<flags>:<url>]
+//
+// The text following such a tag, up to a tag with an empty URL field or "-",
+// can be opened from terminals which support hyperlinks (using the OSC 8
+// escape sequence). Only StreamScreen, which includes inline screens, emits
+// hyperlinks. On other screens, the text is shown without them. Other
+// primitives do not recognize the fourth field.
+func (t *TextView) SetHyperlinks(hyperlinks bool) *TextView {
+	if t.hyperlinks != hyperlinks {
+		t.index = nil
+	}
+	t.hyperlinks = hyperlinks
+	return t
+}
+
+// tagPattern returns the regular expression which matches the color tags of
+// this text view.
+func (t *TextView) tagPattern() *regexp.Regexp {
+	if t.hyperlinks {
+		return linkPattern
+	}
+	return colorPattern
+}
+
+// decompose is like decomposeString() but finds the tags which are enabled for
+// this text view.
+func (t *TextView) decompose(text string) (colorIndices [][]int, colors [][]string, regionIndices [][]int, regions [][]string, escapeIndices [][]int, stripped string, width int) {
+	var pattern *regexp.Regexp
+	if t.dynamicColors {
+		pattern = t.tagPattern()
+	}
+	return decomposeTags(text, pattern, t.regions)
+}
+
 // SetRegions sets the flag that allows to define regions in the text. See class
 // description for details.
 func (t *TextView) SetRegions(regions bool) *TextView {
@@ -314,6 +461,75 @@ func (t *TextView) SetChangedFunc(handler func()) *TextView {
 	return t
 }
 
+// SetMaxLines sets the maximum number of lines the text view keeps, e.g. to
+// limit the memory used by a log which is written to continuously. When more
+// lines are written, the oldest lines are removed. Lines are counted as
+// separated by line breaks, regardless of wrapping. Provide 0 (the default)
+// for no limit.
+//
+// If the text view doesn't track the end of the text (see ScrollToEnd()), its
+// scroll position is moved such that the same text remains visible. Regions
+// which continue past the removed lines are kept intact.
+func (t *TextView) SetMaxLines(maxLines int) *TextView {
+	t.Lock()
+	t.maxLines = maxLines
+	lines := t.trim()
+	trimmed := t.trimmed
+	t.Unlock()
+
+	if lines > 0 && trimmed != nil {
+		trimmed(lines)
+	}
+	return t
+}
+
+// GetMaxLines returns the maximum number of lines set with SetMaxLines().
+func (t *TextView) GetMaxLines() int {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.maxLines
+}
+
+// SetMaxBytes sets the maximum number of bytes of text the text view keeps,
+// including color and region tags but not line breaks. When more text is
+// written, the oldest lines are removed, as with SetMaxLines(). The last line
+// is always kept, even if it is longer than the limit. Provide 0 (the default)
+// for no limit.
+func (t *TextView) SetMaxBytes(maxBytes int) *TextView {
+	t.Lock()
+	t.maxBytes = maxBytes
+	lines := t.trim()
+	trimmed := t.trimmed
+	t.Unlock()
+
+	if lines > 0 && trimmed != nil {
+		trimmed(lines)
+	}
+	return t
+}
+
+// GetMaxBytes returns the maximum number of bytes set with SetMaxBytes().
+func (t *TextView) GetMaxBytes() int {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.maxBytes
+}
+
+// SetTrimmedFunc sets a handler which is called with the number of lines which
+// were removed from the text view to stay within the limits set with
+// SetMaxLines() and SetMaxBytes(). It is called after the text view was
+// unlocked, so the rules for the "changed" handler apply (see
+// SetChangedFunc()).
+func (t *TextView) SetTrimmedFunc(handler func(lines int)) *TextView {
+	t.Lock()
+	defer t.Unlock()
+
+	t.trimmed = handler
+	return t
+}
+
 // SetDoneFunc sets a handler which is called when the user presses on the
 // following keys: Escape, Enter, Tab, Backtab. The key is passed to the
 // handler.
@@ -327,6 +543,9 @@ func (t *TextView) ScrollTo(row, column int) *TextView {
 	if !t.scrollable {
 		return t
 	}
+	t.Lock()
+	defer t.Unlock()
+	t.wrapIndex(0, row, t.lastWidth) // Count rows as they are shown.
 	t.lineOffset = row
 	t.columnOffset = column
 	return t
@@ -359,14 +578,30 @@ func (t *TextView) ScrollToEnd() *TextView {
 // GetScrollOffset returns the number of rows and columns that are skipped at
 // the top left corner when the text view has been scrolled.
 func (t *TextView) GetScrollOffset() (row, column int) {
-	return t.lineOffset, t.columnOffset
+	t.Lock()
+	defer t.Unlock()
+
+	// Count rows as they are shown, including those of lines above which were
+	// not wrapped for the current width yet (see unwrapIndex()).
+	row = t.lineOffset
+	for index := 0; index < t.lineOffset && index < len(t.index); index++ {
+		if line := t.index[index]; line.Stale {
+			_, _, _, _, _, strippedStr, _ := t.decompose(t.buffer[line.Line-t.indexBase])
+			row += len(t.splitLine(strippedStr, t.lastWidth)) - 1
+		}
+	}
+	return row, t.columnOffset
 }
 
 // Clear removes all text from the buffer.
 func (t *TextView) Clear() *TextView {
 	t.buffer = nil
+	t.bufferBytes = 0
 	t.recentBytes = nil
+	t.firstRegion = ""
 	t.index = nil
+	t.searchLine = -1
+	t.selected = false
 	return t
 }
 
@@ -386,7 +621,6 @@ func (t *TextView) Highlight(regionIDs ...string) *TextView {
 		}
 		t.highlights[id] = struct{}{}
 	}
-	t.index = nil
 	return t
 }
 
@@ -410,7 +644,6 @@ func (t *TextView) ScrollToHighlight() *TextView {
 	if len(t.highlights) == 0 || !t.scrollable || !t.regions {
 		return t
 	}
-	t.index = nil
 	t.scrollToHighlights = true
 	t.trackEnd = false
 	return t
@@ -427,16 +660,14 @@ func (t *TextView) GetRegionText(regionID string) string {
 		return ""
 	}
 
-	var (
-		buffer          bytes.Buffer
-		currentRegionID string
-	)
+	var buffer bytes.Buffer
+	currentRegionID := t.firstRegion
 
 	for _, str := range t.buffer {
 		// Find all color tags in this line.
 		var colorTagIndices [][]int
 		if t.dynamicColors {
-			colorTagIndices = colorPattern.FindAllStringIndex(str, -1)
+			colorTagIndices = t.tagPattern().FindAllStringIndex(str, -1)
 		}
 
 		// Find all regions in this line.
@@ -488,6 +719,493 @@ func (t *TextView) GetRegionText(regionID string) string {
 	return escapePattern.ReplaceAllString(buffer.String(), `[$1$2]`)
 }
 
+// SetRegionClickedFunc sets a handler which is called when the user clicks on
+// a region (see SetRegions()) with the left mouse button. It receives the
+// region's ID and the mouse event. While a handler is installed, regions under
+// the mouse pointer are highlighted, and the Tab and Backtab keys highlight the
+// next or the previous region (in the order of their first appearance in the
+// text) and scroll to it (see Highlight() and ScrollToHighlight()). Enter then
+// calls the handler with a nil event. Tab on the last region and Backtab on the
+// first region remove the highlight and are passed to the handler installed
+// with SetDoneFunc(), so that the focus can move on:
+//
+//   textView.SetRegionClickedFunc(func(regionID string, event *tview.MouseEvent) {
+//     showPage(regionID)
+//   })
+//
+// Provide nil to uninstall the handler.
+func (t *TextView) SetRegionClickedFunc(handler func(regionID string, event *MouseEvent)) *TextView {
+	t.regionClicked = handler
+	if handler == nil {
+		t.hoverRegion = ""
+	}
+	return t
+}
+
+// regionIDs returns the IDs of all regions in the order of their first
+// appearance in the text.
+func (t *TextView) regionIDs() (regionIDs []string) {
+	t.Lock()
+	defer t.Unlock()
+
+	found := make(map[string]bool)
+	if t.firstRegion != "" {
+		found[t.firstRegion] = true
+		regionIDs = append(regionIDs, t.firstRegion)
+	}
+	for _, str := range t.buffer {
+		for _, region := range regionPattern.FindAllStringSubmatch(str, -1) {
+			if id := region[1]; id != "" && !found[id] {
+				found[id] = true
+				regionIDs = append(regionIDs, id)
+			}
+		}
+	}
+	return
+}
+
+// regionAt returns the ID of the region drawn at the given screen position or
+// an empty string if there is none.
+func (t *TextView) regionAt(x, y int) string {
+	for _, region := range t.drawnRegions {
+		if y == region.Y && x >= region.X && x < region.X+region.Width {
+			return region.ID
+		}
+	}
+	return ""
+}
+
+// cycleRegions handles the keys which move between regions and activate them
+// (see SetRegionClickedFunc()). It returns false if the key is to be passed
+// to the "done" handler.
+func (t *TextView) cycleRegions(key tcell.Key) bool {
+	var current string
+	if len(t.highlights) == 1 {
+		for regionID := range t.highlights {
+			current = regionID
+		}
+	}
+
+	switch key {
+	case tcell.KeyEnter:
+		if current == "" {
+			return false
+		}
+		t.regionClicked(current, nil)
+		return true
+	case tcell.KeyTab, tcell.KeyBacktab:
+		regionIDs := t.regionIDs()
+		index := -1
+		for i, regionID := range regionIDs {
+			if regionID == current {
+				index = i
+			}
+		}
+		if key == tcell.KeyTab {
+			index++
+		} else if index < 0 {
+			index = len(regionIDs) - 1
+		} else {
+			index--
+		}
+		if index < 0 || index >= len(regionIDs) {
+			t.Highlight()
+			return false
+		}
+		t.Highlight(regionIDs[index]).ScrollToHighlight()
+		return true
+	}
+
+	return false
+}
+
+// SetSelectable sets the flag that decides whether or not the user can select
+// text by dragging the mouse over it and, if the text view is scrollable, by
+// pressing the arrow keys while holding Shift. The first key press starts the
+// selection at the beginning of the first visible line. Selected text is
+// highlighted and returned by GetSelection().
+func (t *TextView) SetSelectable(selectable bool) *TextView {
+	t.Lock()
+	defer t.Unlock()
+
+	t.selectable = selectable
+	if !selectable {
+		t.selected = false
+	}
+	return t
+}
+
+// SetCopyFunc sets a handler which is called with the selected text (see
+// GetSelection()) when the user releases the mouse button after selecting text
+// with the mouse, e.g. to copy it to the clipboard:
+//
+//   textView.SetSelectable(true).SetCopyFunc(app.SetClipboard)
+func (t *TextView) SetCopyFunc(handler func(text string)) *TextView {
+	t.copied = handler
+	return t
+}
+
+// GetSelection returns the text selected by the user (see SetSelectable())
+// with all tags removed, like GetText(true) does, or an empty string if no text
+// is selected. Line breaks are returned as '\n' runes.
+func (t *TextView) GetSelection() string {
+	t.Lock()
+	defer t.Unlock()
+
+	if !t.selected {
+		return ""
+	}
+
+	from, to := t.selectionRange()
+	var buffer bytes.Buffer
+	for line := from.Line; line <= to.Line; line++ {
+		if line < t.indexBase || line-t.indexBase >= len(t.buffer) {
+			continue // This line was removed.
+		}
+		if line > from.Line && line > t.indexBase {
+			buffer.WriteByte('\n')
+		}
+		_, _, _, _, _, strippedStr, _ := t.decompose(t.buffer[line-t.indexBase])
+		start, end := 0, len(strippedStr)
+		if line == from.Line && from.Pos < end {
+			start = from.Pos
+		}
+		if line == to.Line && to.Pos < end {
+			end = to.Pos
+		}
+		if start < end {
+			buffer.WriteString(strippedStr[start:end])
+		}
+	}
+	return buffer.String()
+}
+
+// ClearSelection removes the selection (see SetSelectable()).
+func (t *TextView) ClearSelection() *TextView {
+	t.Lock()
+	defer t.Unlock()
+
+	t.selected = false
+	return t
+}
+
+// selectionRange returns the start and the end of the selection, where the end
+// is the position after the last selected character.
+func (t *TextView) selectionRange() (from, to textViewPosition) {
+	from, to = t.selectionAnchor, t.selectionCursor
+	if to.before(from) {
+		from, to = to, from
+	}
+
+	// Include the last character.
+	if line := to.Line - t.indexBase; line >= 0 && line < len(t.buffer) {
+		_, _, _, _, _, strippedStr, _ := t.decompose(t.buffer[line])
+		if to.Pos < len(strippedStr) {
+			iterateString(strippedStr[to.Pos:], func(main rune, comb []rune, textPos, textWidth, screenPos, screenWidth int) bool {
+				to.Pos += textWidth
+				return true
+			})
+		}
+	}
+	return
+}
+
+// positionAt returns the position of the text shown at the given coordinates
+// relative to the text view's top-left corner. Positions above or below the
+// text are mapped to the beginning of the first or the end of the last
+// visible line.
+func (t *TextView) positionAt(x, y int) textViewPosition {
+	if len(t.index) == 0 {
+		return textViewPosition{Line: t.indexBase}
+	}
+	rectX, rectY, _, _ := t.GetRect()
+	innerX, innerY, width, _ := t.GetInnerRect()
+	x, y = x-innerX+rectX, y-innerY+rectY
+	if y < 0 {
+		x, y = -1, 0
+	} else if y >= t.pageSize {
+		x, y = width, t.pageSize-1
+	}
+
+	line := t.lineOffset + y
+	if line >= len(t.index) {
+		line, x = len(t.index)-1, width
+	}
+	if line < 0 {
+		line = 0
+	}
+	t.wrapIndex(line, line+1, width)
+
+	// Where does the line start on screen? (See Draw().)
+	index := t.index[line]
+	var startX int
+	if t.align == AlignLeft {
+		startX = -t.columnOffset
+	} else if t.align == AlignRight {
+		startX = width - index.Width - t.columnOffset
+	} else { // AlignCenter.
+		startX = (width-index.Width)/2 - t.columnOffset
+	}
+	if startX < 0 && t.wrap {
+		startX = 0
+	}
+	if x >= width {
+		x = index.Width + startX // After the last character.
+	}
+
+	return textViewPosition{Line: index.Line, Pos: t.indexPosition(line, x-startX)}
+}
+
+// moveSelection moves the end of the selection according to the given arrow
+// key, starting a new selection if there is none, and scrolls it into view.
+func (t *TextView) moveSelection(key tcell.Key) {
+	t.Lock()
+	defer t.Unlock()
+
+	if len(t.index) == 0 {
+		return
+	}
+	if !t.selected || t.selectionCursor.Line < t.indexBase {
+		line := t.lineOffset
+		if line < 0 || line >= len(t.index) {
+			line = 0
+		}
+		t.selectionAnchor = textViewPosition{Line: t.index[line].Line, Pos: t.index[line].StrippedPos}
+		t.selectionCursor = t.selectionAnchor
+		t.selected = true
+	}
+
+	cursor := t.selectionCursor
+	switch key {
+	case tcell.KeyLeft, tcell.KeyRight:
+		line := cursor.Line - t.indexBase
+		if line >= len(t.buffer) {
+			return
+		}
+		_, _, _, _, _, strippedStr, _ := t.decompose(t.buffer[line])
+		if key == tcell.KeyLeft && cursor.Pos > 0 {
+			iterateStringReverse(strippedStr[:cursor.Pos], func(main rune, comb []rune, textPos, textWidth, screenPos, screenWidth int) bool {
+				cursor.Pos -= textWidth
+				return true
+			})
+		} else if key == tcell.KeyLeft && line > 0 {
+			_, _, _, _, _, strippedStr, _ = t.decompose(t.buffer[line-1])
+			cursor = textViewPosition{Line: cursor.Line - 1, Pos: len(strippedStr)}
+		} else if key == tcell.KeyRight && cursor.Pos < len(strippedStr) {
+			iterateString(strippedStr[cursor.Pos:], func(main rune, comb []rune, textPos, textWidth, screenPos, screenWidth int) bool {
+				cursor.Pos += textWidth
+				return true
+			})
+		} else if key == tcell.KeyRight && line < len(t.buffer)-1 {
+			cursor = textViewPosition{Line: cursor.Line + 1}
+		}
+	case tcell.KeyUp, tcell.KeyDown:
+		index := t.findIndex(cursor, t.lastWidth)
+		if index < 0 {
+			return
+		}
+		column := t.indexColumn(index, cursor.Pos)
+		if key == tcell.KeyUp {
+			if index == 0 {
+				return
+			}
+			index--
+			length := len(t.index)
+			t.wrapIndex(index, index+1, t.lastWidth)
+			index += len(t.index) - length // Move to the last line of a wrapped line.
+		} else {
+			if index >= len(t.index)-1 {
+				return
+			}
+			index++
+			t.wrapIndex(index, index+1, t.lastWidth)
+		}
+		cursor = textViewPosition{Line: t.index[index].Line, Pos: t.indexPosition(index, column)}
+	}
+	t.selectionCursor = cursor
+
+	// Keep the cursor visible.
+	index := t.findIndex(cursor, t.lastWidth)
+	if index < 0 {
+		return
+	}
+	if index < t.lineOffset || t.trackEnd && index < len(t.index)-t.pageSize {
+		t.trackEnd = false
+		t.lineOffset = index
+	} else if index >= t.lineOffset+t.pageSize {
+		t.lineOffset = index - t.pageSize + 1
+	}
+	if column := t.indexColumn(index, cursor.Pos); column < t.columnOffset {
+		t.columnOffset = column
+	} else if column >= t.columnOffset+t.lastWidth {
+		t.columnOffset = column - t.lastWidth + 1
+	}
+}
+
+// SetSearch sets the pattern to search for in the text view, either a literal
+// string or, if isRegexp is true, a regular expression (see the regexp
+// package). All matches are highlighted like highlighted regions. Matching
+// ignores color and region tags, but not the case of letters unless the regular
+// expression asks for it, e.g. with "(?i)". A match does not span multiple
+// lines of the buffer. Text written to the text view later is searched, too.
+//
+// SearchNext() and SearchPrevious() scroll to the matches. Provide an empty
+// pattern to remove the search. An error is returned if the regular
+// expression is invalid, in which case the previous search remains.
+func (t *TextView) SetSearch(pattern string, isRegexp bool) error {
+	var search *regexp.Regexp
+	if pattern != "" {
+		if !isRegexp {
+			pattern = regexp.QuoteMeta(pattern)
+		}
+		var err error
+		search, err = regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	t.searchPattern = pattern
+	t.search = search
+	t.searchLine = -1
+	t.searchMatches = nil
+	return nil
+}
+
+// GetSearch returns the pattern set with SetSearch() as a regular expression
+// or an empty string if there is no search.
+func (t *TextView) GetSearch() string {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.searchPattern
+}
+
+// SearchNext makes the next match of the search (see SetSearch()) the current
+// match and scrolls to it the next time the text view is drawn, the same way
+// ScrollToHighlight() does. If there is no current match, the search starts at
+// the first visible line. At the end of the text, it continues at the
+// beginning. Returns false if there is no match or if the text view is not
+// scrollable.
+func (t *TextView) SearchNext() bool {
+	return t.searchMatch(false)
+}
+
+// SearchPrevious is like SearchNext() but moves to the previous match. At the
+// beginning of the text, the search continues at the end.
+func (t *TextView) SearchPrevious() bool {
+	return t.searchMatch(true)
+}
+
+// SetSearchPrompt sets the flag that decides whether or not the user can
+// search the text with a prompt which is opened with the "/" key (to search
+// forward) or the "?" key (to search backwards) in the last line of the text
+// view. The text entered there is a regular expression or, if it isn't valid,
+// a literal string (see SetSearch()). Matches are highlighted while typing.
+// Enter moves to the first match, Escape cancels the search. Afterwards, "n"
+// moves to the next match in the same direction and "N" in the opposite
+// direction. These keys are only available if the text view is scrollable.
+func (t *TextView) SetSearchPrompt(enabled bool) *TextView {
+	t.searchPrompt = enabled
+	return t
+}
+
+// searchMatch finds the next or previous match of the search after or before
+// the current match or the first visible line and makes it the current match.
+// It returns false if there is no such match.
+func (t *TextView) searchMatch(backwards bool) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.search == nil || !t.scrollable || len(t.buffer) == 0 {
+		return false
+	}
+
+	// Where do we start?
+	var line, pos int
+	if t.searchLine >= t.indexBase && t.searchLine-t.indexBase < len(t.buffer) {
+		line, pos = t.searchLine-t.indexBase, t.searchFrom
+		if !backwards {
+			pos++
+		}
+	} else if t.index != nil && t.lineOffset >= 0 && t.lineOffset < len(t.index) {
+		line, pos = t.index[t.lineOffset].Line-t.indexBase, t.index[t.lineOffset].StrippedPos
+	}
+
+	// Go through all lines, wrapping around, until we come back to where we
+	// started.
+	for count := 0; count <= len(t.buffer); count++ {
+		_, _, _, _, _, strippedStr, _ := t.decompose(t.buffer[line])
+		var match []int
+		for _, m := range t.search.FindAllStringIndex(strippedStr, -1) {
+			if m[0] == m[1] {
+				continue // Empty matches can't be highlighted.
+			}
+			if count == 0 && (backwards && m[0] >= pos || !backwards && m[0] < pos) ||
+				count == len(t.buffer) && (backwards && m[0] < pos || !backwards && m[0] >= pos) {
+				continue // Not in the remaining part of the start line.
+			}
+			match = m
+			if !backwards {
+				break
+			}
+		}
+		if match != nil {
+			t.searchLine, t.searchFrom, t.searchTo = line+t.indexBase, match[0], match[1]
+			t.scrollToMatch = true
+			t.trackEnd = false
+			return true
+		}
+
+		// Next line.
+		if backwards {
+			line--
+			if line < 0 {
+				line = len(t.buffer) - 1
+			}
+		} else {
+			line++
+			if line >= len(t.buffer) {
+				line = 0
+			}
+		}
+	}
+
+	return false
+}
+
+// openSearchPrompt opens the search prompt for searching forward or
+// backwards.
+func (t *TextView) openSearchPrompt(backwards bool) {
+	label := "/"
+	if backwards {
+		label = "?"
+	}
+	pattern, previous := t.GetSearch(), t.search
+	t.searchField = NewInputField().
+		SetLabel(label).
+		SetChangedFunc(func(text string) {
+			if t.SetSearch(text, true) != nil {
+				t.SetSearch(text, false)
+			}
+		}).
+		SetDoneFunc(func(key tcell.Key) {
+			t.searchField = nil
+			if key == tcell.KeyEscape {
+				t.Lock()
+				t.searchPattern, t.search = pattern, previous
+				t.Unlock()
+				return
+			}
+			t.searchBackwards = backwards
+			t.searchMatch(backwards)
+		})
+	t.searchField.Focus(nil)
+}
+
 // Focus is called when this primitive receives focus.
 func (t *TextView) Focus(delegate func(p Primitive)) {
 	// Implemented here with locking because this is used by layout primitives.
@@ -511,11 +1229,19 @@ func (t *TextView) HasFocus() bool {
 func (t *TextView) Write(p []byte) (n int, err error) {
 	// Notify at the end.
 	t.Lock()
-	changed := t.changed
+	changed, trimmed := t.changed, t.trimmed
 	t.Unlock()
 	if changed != nil {
 		defer changed() // Deadlocks may occur if we lock here.
 	}
+	var trimmedLines int
+	if trimmed != nil {
+		defer func() {
+			if trimmedLines > 0 {
+				trimmed(trimmedLines)
+			}
+		}()
+	}
 
 	t.Lock()
 	defer t.Unlock()
@@ -548,6 +1274,15 @@ func (t *TextView) Write(p []byte) (n int, err error) {
 		}
 	}
 
+	// Only the last line and new lines need to be indexed and searched again.
+	delete(t.searchMatches, len(t.buffer)-1+t.indexBase)
+	if lastLine := len(t.buffer) - 1; lastLine < t.indexedLines {
+		t.indexedLines = lastLine
+		if t.indexedLines < 0 {
+			t.indexedLines = 0
+		}
+	}
+
 	// Transform the new bytes into strings.
 	newBytes = bytes.Replace(newBytes, []byte{'\t'}, bytes.Repeat([]byte{' '}, TabSize), -1)
 	for index, line := range newLineRegex.Split(string(newBytes), -1) {
@@ -560,188 +1295,574 @@ func (t *TextView) Write(p []byte) (n int, err error) {
 		} else {
 			t.buffer = append(t.buffer, line)
 		}
+		t.bufferBytes += len(line)
 	}
 
-	// Reset the index.
-	t.index = nil
+	// Remove old lines if we have too many.
+	trimmedLines = t.trim()
 
 	return len(p), nil
 }
 
+// trim removes the oldest lines from the buffer until it is within the limits
+// set with SetMaxLines() and SetMaxBytes(), keeping at least the last line. It
+// returns the number of lines removed. The text view must be locked.
+func (t *TextView) trim() int {
+	var lines int
+	size := t.bufferBytes
+	for lines < len(t.buffer)-1 && (t.maxLines > 0 && len(t.buffer)-lines > t.maxLines || t.maxBytes > 0 && size > t.maxBytes) {
+		size -= len(t.buffer[lines])
+		lines++
+	}
+	if lines == 0 {
+		return 0
+	}
+
+	t.removeLines(lines)
+	return lines
+}
+
+// removeLines removes the given number of lines from the start of the buffer
+// and from the index, keeping the same text in view. The text view must be
+// locked.
+func (t *TextView) removeLines(lines int) {
+	// Find the region which continues after the removed lines. Colors and
+	// attributes start over with each buffer line (see indexLine()), so it is
+	// the only state which needs to be carried over.
+	t.firstRegion = t.lineRegion(lines)
+
+	// Remove the index lines of the removed lines and keep the same text in
+	// view. Index lines, the selection, and search results refer to buffer
+	// lines relative to indexBase, so they remain valid.
+	var indexLines int
+	if t.index != nil {
+		for indexLines < len(t.index) && t.index[indexLines].Line-t.indexBase < lines {
+			t.index[indexLines] = nil // Release the memory.
+			indexLines++
+		}
+		t.index = t.index[indexLines:]
+		t.indexBase += lines
+		t.indexedLines -= lines
+		if t.indexedLines < 0 {
+			t.indexedLines = 0
+		}
+	} else {
+		for _, str := range t.buffer[:lines] {
+			_, _, _, _, _, strippedStr, _ := t.decompose(str)
+			indexLines += len(t.splitLine(strippedStr, t.lastWidth))
+		}
+	}
+	if !t.trackEnd && t.lineOffset > 0 {
+		t.lineOffset -= indexLines
+		if t.lineOffset < 0 {
+			t.lineOffset = 0
+		}
+	}
+
+	t.dropLines(lines)
+}
+
+// lineRegion returns the ID of the region in which the given buffer line
+// starts, as determined when indexing it, or an empty string if it starts
+// outside of any region. The text view must be locked.
+func (t *TextView) lineRegion(line int) string {
+	if !t.regions {
+		return ""
+	}
+
+	// Is the line indexed?
+	index := sort.Search(len(t.index), func(i int) bool {
+		return t.index[i].Line >= line+t.indexBase
+	})
+	if index < len(t.index) && t.index[index].Line == line+t.indexBase {
+		return t.index[index].Region
+	}
+
+	// No. Find the last region tag before it.
+	for line--; line >= 0; line-- {
+		_, _, _, regions, _, _, _ := decomposeString(t.buffer[line], false, true)
+		if len(regions) > 0 {
+			return regions[len(regions)-1][1]
+		}
+	}
+	return t.firstRegion
+}
+
+// dropLines removes the given number of lines from the start of the buffer.
+// The text view must be locked.
+func (t *TextView) dropLines(lines int) {
+	for index := 0; index < lines; index++ {
+		t.bufferBytes -= len(t.buffer[index])
+		t.buffer[index] = "" // Release the memory.
+	}
+	t.buffer = t.buffer[lines:]
+}
+
+// splitLine splits the given line, stripped of all tags, into the parts which
+// are shown on separate lines when the text view is the given number of cells
+// wide.
+func (t *TextView) splitLine(str string, width int) []string {
+	if !t.wrap || len(str) == 0 || width < 1 {
+		return []string{str}
+	}
+
+	var splitLines []string
+	for len(str) > 0 {
+		extract := runewidth.Truncate(str, width, "")
+		if t.wordWrap && len(extract) < len(str) {
+			// Add any spaces from the next line.
+			if spaces := spacePattern.FindStringIndex(str[len(extract):]); spaces != nil && spaces[0] == 0 {
+				extract = str[:len(extract)+spaces[1]]
+			}
+
+			// Can we split before the mandatory end?
+			matches := boundaryPattern.FindAllStringIndex(extract, -1)
+			if len(matches) > 0 {
+				// Yes. Let's split there.
+				extract = extract[:matches[len(matches)-1][1]]
+			}
+		}
+		splitLines = append(splitLines, extract)
+		str = str[len(extract):]
+	}
+	return splitLines
+}
+
 // reindexBuffer re-indexes the buffer such that we can use it to easily draw
 // the buffer onto the screen. Each line in the index will contain a pointer
 // into the buffer from which on we will print text. It will also contain the
 // color with which the line starts.
+//
+// If the index exists, only the buffer lines which were added or changed since
+// it was built are indexed (see Write()).
 func (t *TextView) reindexBuffer(width int) {
-	if t.index != nil {
+	if t.index != nil && t.indexedLines >= len(t.buffer) {
 		return // Nothing has changed. We can still use the current index.
 	}
-	t.index = nil
-	t.fromHighlight, t.toHighlight, t.posHighlight = -1, -1, -1
 
 	// If there's no space, there's no index.
 	if width < 1 {
+		t.index = nil
 		return
 	}
 
-	// Initial states.
+	// Start with a new index or remove the lines which need to be indexed
+	// again.
 	regionID := ""
-	var highlighted bool
-
-	// Go through each line in the buffer.
-	for bufferIndex, str := range t.buffer {
-		colorTagIndices, colorTags, regionIndices, regions, escapeIndices, strippedStr, _ := decomposeString(str, t.dynamicColors, t.regions)
-
-		// Split the line if required.
-		var splitLines []string
-		str = strippedStr
-		if t.wrap && len(str) > 0 {
-			for len(str) > 0 {
-				extract := runewidth.Truncate(str, width, "")
-				if t.wordWrap && len(extract) < len(str) {
-					// Add any spaces from the next line.
-					if spaces := spacePattern.FindStringIndex(str[len(extract):]); spaces != nil && spaces[0] == 0 {
-						extract = str[:len(extract)+spaces[1]]
-					}
+	if t.index == nil {
+		t.indexBase, t.indexedLines, t.longestLine = 0, 0, 0
+		t.searchMatches = nil
+	} else {
+		position := len(t.index)
+		for position > 0 && t.index[position-1].Line-t.indexBase >= t.indexedLines {
+			position--
+		}
+		if position < len(t.index) {
+			regionID = t.index[position].Region
+		}
+		t.index = t.index[:position]
+	}
+	if t.indexedLines == 0 {
+		regionID = t.firstRegion
+	}
 
-					// Can we split before the mandatory end?
-					matches := boundaryPattern.FindAllStringIndex(extract, -1)
-					if len(matches) > 0 {
-						// Yes. Let's split there.
-						extract = extract[:matches[len(matches)-1][1]]
-					}
-				}
-				splitLines = append(splitLines, extract)
-				str = str[len(extract):]
+	// Go through each new line in the buffer.
+	for bufferIndex := t.indexedLines; bufferIndex < len(t.buffer); bufferIndex++ {
+		var lines []*textViewIndex
+		lines, regionID = t.indexLine(bufferIndex, regionID, width)
+		for _, line := range lines {
+			if line.Width > t.longestLine {
+				t.longestLine = line.Width
 			}
-		} else {
-			// No need to split the line.
-			splitLines = []string{str}
 		}
+		t.index = append(t.index, lines...)
+	}
+	t.indexedLines = len(t.buffer)
+}
 
-		// Create index from split lines.
-		var (
-			originalPos, colorPos, regionPos, escapePos  int
-			foregroundColor, backgroundColor, attributes string
-		)
-		for _, splitLine := range splitLines {
-			line := &textViewIndex{
-				Line:            bufferIndex,
-				Pos:             originalPos,
-				ForegroundColor: foregroundColor,
-				BackgroundColor: backgroundColor,
-				Attributes:      attributes,
-				Region:          regionID,
-			}
-
-			// Shift original position with tags.
-			lineLength := len(splitLine)
-			remainingLength := lineLength
-			tagEnd := originalPos
-			totalTagLength := 0
-			for {
-				// Which tag comes next?
-				nextTag := make([][3]int, 0, 3)
-				if colorPos < len(colorTagIndices) {
-					nextTag = append(nextTag, [3]int{colorTagIndices[colorPos][0], colorTagIndices[colorPos][1], 0}) // 0 = color tag.
-				}
-				if regionPos < len(regionIndices) {
-					nextTag = append(nextTag, [3]int{regionIndices[regionPos][0], regionIndices[regionPos][1], 1}) // 1 = region tag.
-				}
-				if escapePos < len(escapeIndices) {
-					nextTag = append(nextTag, [3]int{escapeIndices[escapePos][0], escapeIndices[escapePos][1], 2}) // 2 = escape tag.
-				}
-				minPos := -1
-				tagIndex := -1
-				for index, pair := range nextTag {
-					if minPos < 0 || pair[0] < minPos {
-						minPos = pair[0]
-						tagIndex = index
-					}
-				}
-
-				// Is the next tag in range?
-				if tagIndex < 0 || minPos >= tagEnd+remainingLength {
-					break // No. We're done with this line.
-				}
+// indexLine returns the index of the given buffer line, which starts in the
+// region with the given ID, for the given width. The region at the end of the
+// line is also returned.
+func (t *TextView) indexLine(bufferIndex int, regionID string, width int) ([]*textViewIndex, string) {
+	str := t.buffer[bufferIndex]
+	colorTagIndices, colorTags, regionIndices, regions, escapeIndices, strippedStr, _ := t.decompose(str)
 
-				// Advance.
-				strippedTagStart := nextTag[tagIndex][0] - originalPos - totalTagLength
-				tagEnd = nextTag[tagIndex][1]
-				tagLength := tagEnd - nextTag[tagIndex][0]
-				if nextTag[tagIndex][2] == 2 {
-					tagLength = 1
-				}
-				totalTagLength += tagLength
-				remainingLength = lineLength - (tagEnd - originalPos - totalTagLength)
+	// Split the line if required.
+	splitLines := t.splitLine(strippedStr, width)
 
-				// Process the tag.
-				switch nextTag[tagIndex][2] {
-				case 0:
-					// Process color tags.
-					foregroundColor, backgroundColor, attributes = styleFromTag(foregroundColor, backgroundColor, attributes, colorTags[colorPos])
-					colorPos++
-				case 1:
-					// Process region tags.
-					regionID = regions[regionPos][1]
-					_, highlighted = t.highlights[regionID]
-
-					// Update highlight range.
-					if highlighted {
-						line := len(t.index)
-						if t.fromHighlight < 0 {
-							t.fromHighlight, t.toHighlight = line, line
-							t.posHighlight = runewidth.StringWidth(splitLine[:strippedTagStart])
-						} else if line > t.toHighlight {
-							t.toHighlight = line
-						}
-					}
+	// Create index from split lines.
+	var (
+		index                                                    []*textViewIndex
+		originalPos, strippedPos, colorPos, regionPos, escapePos int
+		foregroundColor, backgroundColor, attributes, url        string
+	)
+	for _, splitLine := range splitLines {
+		line := &textViewIndex{
+			Line:            bufferIndex + t.indexBase,
+			Pos:             originalPos,
+			StrippedPos:     strippedPos,
+			ForegroundColor: foregroundColor,
+			BackgroundColor: backgroundColor,
+			Attributes:      attributes,
+			Region:          regionID,
+			URL:             url,
+		}
 
-					regionPos++
-				case 2:
-					// Process escape tags.
-					escapePos++
+		// Shift original position with tags.
+		lineLength := len(splitLine)
+		remainingLength := lineLength
+		tagEnd := originalPos
+		totalTagLength := 0
+		for {
+			// Which tag comes next?
+			nextTag := make([][3]int, 0, 3)
+			if colorPos < len(colorTagIndices) {
+				nextTag = append(nextTag, [3]int{colorTagIndices[colorPos][0], colorTagIndices[colorPos][1], 0}) // 0 = color tag.
+			}
+			if regionPos < len(regionIndices) {
+				nextTag = append(nextTag, [3]int{regionIndices[regionPos][0], regionIndices[regionPos][1], 1}) // 1 = region tag.
+			}
+			if escapePos < len(escapeIndices) {
+				nextTag = append(nextTag, [3]int{escapeIndices[escapePos][0], escapeIndices[escapePos][1], 2}) // 2 = escape tag.
+			}
+			minPos := -1
+			tagIndex := -1
+			for index, pair := range nextTag {
+				if minPos < 0 || pair[0] < minPos {
+					minPos = pair[0]
+					tagIndex = index
 				}
 			}
 
-			// Advance to next line.
-			originalPos += lineLength + totalTagLength
+			// Is the next tag in range?
+			if tagIndex < 0 || minPos >= tagEnd+remainingLength {
+				break // No. We're done with this line.
+			}
 
-			// Append this line.
-			line.NextPos = originalPos
-			line.Width = runewidth.StringWidth(splitLine)
-			t.index = append(t.index, line)
+			// Advance.
+			tagEnd = nextTag[tagIndex][1]
+			tagLength := tagEnd - nextTag[tagIndex][0]
+			if nextTag[tagIndex][2] == 2 {
+				tagLength = 1
+			}
+			totalTagLength += tagLength
+			remainingLength = lineLength - (tagEnd - originalPos - totalTagLength)
+
+			// Process the tag.
+			switch nextTag[tagIndex][2] {
+			case 0:
+				// Process color tags.
+				foregroundColor, backgroundColor, attributes = styleFromTag(foregroundColor, backgroundColor, attributes, colorTags[colorPos])
+				url = urlFromTag(url, colorTags[colorPos])
+				colorPos++
+			case 1:
+				// Process region tags.
+				regionID = regions[regionPos][1]
+				regionPos++
+			case 2:
+				// Process escape tags.
+				escapePos++
+			}
 		}
 
-		// Word-wrapped lines may have trailing whitespace. Remove it.
-		if t.wrap && t.wordWrap {
-			for _, line := range t.index {
-				if line.Line > len(t.buffer)-1 {
-					continue
-				}
+		// Advance to next line.
+		originalPos += lineLength + totalTagLength
+		strippedPos += lineLength
 
-				if line.Pos < 0 || line.NextPos > len(t.buffer[line.Line]) {
-					continue
-				}
+		// Append this line.
+		line.NextPos = originalPos
+		line.Width = runewidth.StringWidth(splitLine)
+		index = append(index, line)
+	}
 
-				str := t.buffer[line.Line][line.Pos:line.NextPos]
-				spaces := spacePattern.FindAllStringIndex(str, -1)
-				if spaces != nil && spaces[len(spaces)-1][1] == len(str) {
-					oldNextPos := line.NextPos
-					line.NextPos -= spaces[len(spaces)-1][1] - spaces[len(spaces)-1][0]
-					line.Width -= runewidth.StringWidth(t.buffer[line.Line][line.NextPos:oldNextPos])
-				}
+	// Word-wrapped lines may have trailing whitespace. Remove it.
+	if t.wrap && t.wordWrap {
+		for _, line := range index {
+			if line.Pos < 0 || line.NextPos > len(str) {
+				continue
+			}
+
+			spaces := spacePattern.FindAllStringIndex(str[line.Pos:line.NextPos], -1)
+			if spaces != nil && spaces[len(spaces)-1][1] == line.NextPos-line.Pos {
+				oldNextPos := line.NextPos
+				line.NextPos -= spaces[len(spaces)-1][1] - spaces[len(spaces)-1][0]
+				line.Width -= runewidth.StringWidth(str[line.NextPos:oldNextPos])
 			}
 		}
 	}
 
-	// Calculate longest line.
-	t.longestLine = 0
+	return index, regionID
+}
+
+// unwrapIndex replaces the index lines of each buffer line with a single stale
+// line, to be wrapped again when it is shown (see wrapIndex()). This is done
+// when the width of the text view changes so that resizing doesn't wrap the
+// entire buffer. The scroll position is kept on the same buffer line.
+func (t *TextView) unwrapIndex() {
+	if t.lineOffset >= 0 && t.lineOffset < len(t.index) {
+		t.lineOffset = t.index[t.lineOffset].Line - t.indexBase
+	}
+
+	index := make([]*textViewIndex, 0, len(t.buffer))
 	for _, line := range t.index {
-		if line.Width > t.longestLine {
-			t.longestLine = line.Width
+		if len(index) == 0 || index[len(index)-1].Line != line.Line {
+			index = append(index, &textViewIndex{
+				Line:   line.Line,
+				Region: line.Region,
+				Stale:  true,
+			})
+		}
+	}
+	t.index = index
+	t.longestLine = 0
+}
+
+// wrapIndex wraps the buffer lines of the stale index lines (see unwrapIndex())
+// among the index lines from "start" to "end" (exclusive) for the given width.
+// The line offset is moved along so that the same text stays in view. Index
+// lines before "start" keep their positions.
+func (t *TextView) wrapIndex(start, end, width int) {
+	if start < 0 {
+		start = 0
+	}
+	if end > len(t.index) {
+		end = len(t.index)
+	}
+	if start >= end {
+		return
+	}
+
+	var (
+		wrapped    []*textViewIndex
+		stale      bool
+		lineOffset = t.lineOffset
+	)
+	for position, line := range t.index[start:end] {
+		if start+position == t.lineOffset {
+			lineOffset = start + len(wrapped)
+		}
+		if !line.Stale {
+			wrapped = append(wrapped, line)
+			continue
+		}
+		stale = true
+		lines, _ := t.indexLine(line.Line-t.indexBase, line.Region, width)
+		for _, line := range lines {
+			if line.Width > t.longestLine {
+				t.longestLine = line.Width
+			}
+		}
+		wrapped = append(wrapped, lines...)
+	}
+	if !stale {
+		return
+	}
+
+	index := make([]*textViewIndex, 0, len(t.index)-(end-start)+len(wrapped))
+	index = append(index, t.index[:start]...)
+	index = append(index, wrapped...)
+	t.index = append(index, t.index[end:]...)
+	if t.lineOffset >= end {
+		lineOffset = t.lineOffset + len(wrapped) - (end - start)
+	}
+	t.lineOffset = lineOffset
+}
+
+// wrapAbove wraps the given number of index lines above the line offset (see
+// wrapIndex()) such that at least as many index lines above it are not stale
+// anymore, e.g. before scrolling up.
+func (t *TextView) wrapAbove(lines int) {
+	t.wrapIndex(t.lineOffset-lines, t.lineOffset, t.lastWidth)
+}
+
+// scrollUp scrolls up by the given number of lines, wrapping them first if
+// needed (see wrapAbove()).
+func (t *TextView) scrollUp(lines int) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.trackEnd = false
+	t.wrapAbove(lines)
+	t.lineOffset -= lines
+}
+
+// scrollIntoView scrolls such that the index lines from "from" to "to" are
+// visible, centering them if they fit, and such that the given screen column of
+// the first line is visible. Both index lines must not be stale.
+func (t *TextView) scrollIntoView(from, to, column, width, height int) {
+	// Wrap the lines above and between them so that they are counted as they
+	// are shown.
+	length := len(t.index)
+	t.wrapIndex(from-height, from, width)
+	from += len(t.index) - length
+	to += len(t.index) - length
+	length = len(t.index)
+	if end := from + height; end < to {
+		t.wrapIndex(from+1, end, width)
+	} else {
+		t.wrapIndex(from+1, to, width)
+	}
+	to += len(t.index) - length
+
+	// Do we fit the entire height?
+	if to-from+1 < height {
+		// Yes, let's center the lines.
+		t.lineOffset = (from + to - height) / 2
+	} else {
+		// No, let's move to the first line.
+		t.lineOffset = from
+	}
+
+	// If the column is too far to the right, move it to the middle.
+	if column-t.columnOffset > 3*width/4 {
+		t.columnOffset = column - width/2
+	}
+
+	// If the column is off-screen on the left, move it on-screen.
+	if column-t.columnOffset < 0 {
+		t.columnOffset = column - width/4
+	}
+}
+
+// findMatch returns the first and the last index line of the current match,
+// wrapping them for the given width if needed, and the screen column where
+// the match starts in the first line. The index lines are -1 if the match
+// is not in the index.
+func (t *TextView) findMatch(width int) (from, to, column int) {
+	from = t.findIndex(textViewPosition{Line: t.searchLine, Pos: t.searchFrom}, width)
+	if from < 0 {
+		return -1, -1, 0
+	}
+	to = t.findIndex(textViewPosition{Line: t.searchLine, Pos: t.searchTo - 1}, width)
+	return from, to, t.indexColumn(from, t.searchFrom)
+}
+
+// findHighlights returns the first index line with the tag of a highlighted
+// region and the last one, wrapping them for the given width if needed, and the
+// screen column of the first tag in its line. The index lines are -1 if there
+// are no such tags in the index.
+func (t *TextView) findHighlights(width int) (from, to, column int) {
+	first, last := -1, -1
+	_, carried := t.highlights[t.firstRegion]
+	carried = carried && t.firstRegion != ""
+	if carried && len(t.buffer) > 0 {
+		first, last = 0, 0 // The region continues from removed lines.
+	}
+	for line, str := range t.buffer {
+		if !strings.Contains(str, `["`) {
+			continue
 		}
+		if t.highlightedTag(str) >= 0 {
+			if first < 0 {
+				first = line
+			}
+			last = line
+		}
+	}
+	if first < 0 {
+		return -1, -1, 0
+	}
+
+	// Find the index lines with the first and the last tag. Wrapping the last
+	// line doesn't move the first one.
+	from = t.findIndex(textViewPosition{Line: first + t.indexBase}, width)
+	to = t.findIndex(textViewPosition{Line: last + t.indexBase}, width)
+	if from < 0 || to < 0 {
+		return -1, -1, 0
+	}
+	for !carried && t.highlightedTag(t.indexTags(from)) < 0 && from+1 < len(t.index) && t.index[from+1].Line == t.index[from].Line {
+		from++
+	}
+	for to+1 < len(t.index) && t.index[to+1].Line == t.index[to].Line && t.highlightedTag(t.indexTags(to+1)) >= 0 {
+		to++
+	}
+
+	// Where does the first tag start on screen?
+	tags := t.indexTags(from)
+	if pos := t.highlightedTag(tags); !carried && pos > 0 {
+		_, _, _, _, _, strippedStr, _ := t.decompose(tags[:pos])
+		column = runewidth.StringWidth(strippedStr)
+	}
+	return
+}
+
+// highlightedTag returns the start of the first tag of a highlighted region in
+// the given text or -1 if there is none.
+func (t *TextView) highlightedTag(text string) int {
+	for _, region := range regionPattern.FindAllStringSubmatchIndex(text, -1) {
+		if _, ok := t.highlights[text[region[2]:region[3]]]; ok {
+			return region[0]
+		}
+	}
+	return -1
+}
+
+// indexTags returns the text of the given index line including all tags up to
+// the next index line.
+func (t *TextView) indexTags(index int) string {
+	line := t.index[index]
+	str := t.buffer[line.Line-t.indexBase]
+	if line.Stale {
+		return str
+	}
+	end := len(str)
+	if index+1 < len(t.index) && t.index[index+1].Line == line.Line {
+		end = t.index[index+1].Pos
+	}
+	return str[line.Pos:end]
+}
+
+// findIndex returns the index line which shows the given position, wrapping
+// its buffer line for the given width if needed, or -1 if the buffer line is
+// not in the index.
+func (t *TextView) findIndex(position textViewPosition, width int) int {
+	index := sort.Search(len(t.index), func(i int) bool {
+		return t.index[i].Line >= position.Line
+	})
+	if index >= len(t.index) || t.index[index].Line != position.Line {
+		return -1
+	}
+	t.wrapIndex(index, index+1, width)
+	for index+1 < len(t.index) && t.index[index+1].Line == position.Line && t.index[index+1].StrippedPos <= position.Pos {
+		index++
 	}
+	return index
+}
+
+// indexText returns the text of the given index line with all tags removed.
+func (t *TextView) indexText(index int) string {
+	line := t.index[index]
+	if line.Stale {
+		return ""
+	}
+	_, _, _, _, _, strippedStr, _ := t.decompose(t.buffer[line.Line-t.indexBase][line.Pos:line.NextPos])
+	return strippedStr
+}
+
+// indexColumn returns the screen column of the given position (like
+// textViewIndex.StrippedPos) within the given index line.
+func (t *TextView) indexColumn(index, pos int) int {
+	strippedStr := t.indexText(index)
+	if pos -= t.index[index].StrippedPos; pos >= 0 && pos < len(strippedStr) {
+		strippedStr = strippedStr[:pos]
+	}
+	return runewidth.StringWidth(strippedStr)
+}
+
+// indexPosition returns the position (like textViewIndex.StrippedPos) of the
+// character at the given screen column within the given index line, or the
+// position after the last character if the column is beyond it.
+func (t *TextView) indexPosition(index, column int) int {
+	strippedStr := t.indexText(index)
+	pos := len(strippedStr)
+	iterateString(strippedStr, func(main rune, comb []rune, textPos, textWidth, screenPos, screenWidth int) bool {
+		if column < screenPos+screenWidth {
+			pos = textPos
+			return true
+		}
+		return false
+	})
+	return t.index[index].StrippedPos + pos
 }
 
 // Draw draws this primitive onto the screen.
@@ -752,11 +1873,21 @@ func (t *TextView) Draw(screen tcell.Screen) {
 
 	// Get the available size.
 	x, y, width, height := t.GetInnerRect()
+	t.drawnRegions = t.drawnRegions[:0]
+
+	// The search prompt takes the last line.
+	if t.searchField != nil && height > 0 {
+		height--
+		field := t.searchField
+		field.SetRect(x, y+height, width, 1)
+		defer field.Draw(screen)
+	}
 	t.pageSize = height
 
-	// If the width has changed, we need to reindex.
-	if width != t.lastWidth && t.wrap {
-		t.index = nil
+	// If the width has changed, lines need to be wrapped again. This is done
+	// for the visible lines only.
+	if width != t.lastWidth && t.wrap && t.index != nil {
+		t.unwrapIndex()
 	}
 	t.lastWidth = width
 
@@ -769,27 +1900,28 @@ func (t *TextView) Draw(screen tcell.Screen) {
 	}
 
 	// Move to highlighted regions.
-	if t.regions && t.scrollToHighlights && t.fromHighlight >= 0 {
-		// Do we fit the entire height?
-		if t.toHighlight-t.fromHighlight+1 < height {
-			// Yes, let's center the highlights.
-			t.lineOffset = (t.fromHighlight + t.toHighlight - height) / 2
-		} else {
-			// No, let's move to the start of the highlights.
-			t.lineOffset = t.fromHighlight
+	if t.regions && t.scrollToHighlights {
+		if from, to, column := t.findHighlights(width); from >= 0 {
+			t.scrollIntoView(from, to, column, width, height)
 		}
+	}
+	t.scrollToHighlights = false
 
-		// If the highlight is too far to the right, move it to the middle.
-		if t.posHighlight-t.columnOffset > 3*width/4 {
-			t.columnOffset = t.posHighlight - width/2
+	// Move to the current match.
+	if t.scrollToMatch && t.search != nil {
+		if from, to, column := t.findMatch(width); from >= 0 {
+			t.scrollIntoView(from, to, column, width, height)
 		}
+	}
+	t.scrollToMatch = false
 
-		// If the highlight is off-screen on the left, move it on-screen.
-		if t.posHighlight-t.columnOffset < 0 {
-			t.columnOffset = t.posHighlight - width/4
-		}
+	// Wrap the visible lines if needed.
+	if !t.trackEnd && t.lineOffset >= 0 {
+		t.wrapIndex(t.lineOffset, t.lineOffset+height, width)
+	}
+	if t.trackEnd || t.lineOffset+height > len(t.index) {
+		t.wrapIndex(len(t.index)-height, len(t.index), width)
 	}
-	t.scrollToHighlights = false
 
 	// Adjust line offset.
 	if t.lineOffset+height > len(t.index) {
@@ -832,6 +1964,19 @@ func (t *TextView) Draw(screen tcell.Screen) {
 	}
 
 	// Draw the buffer.
+	var (
+		matches              [][]int
+		matchesLine          = -1
+		searchMatches        map[int][][]int
+		selectFrom, selectTo textViewPosition
+	)
+	if t.search != nil {
+		searchMatches = make(map[int][][]int)
+	}
+	if t.selected {
+		selectFrom, selectTo = t.selectionRange()
+	}
+	linker, _ := screen.(interface{ SetHyperlink(x, y int, url string) })
 	defaultStyle := tcell.StyleDefault.Foreground(t.textColor)
 	for line := t.lineOffset; line < len(t.index); line++ {
 		// Are we done?
@@ -841,24 +1986,36 @@ func (t *TextView) Draw(screen tcell.Screen) {
 
 		// Get the text for this line.
 		index := t.index[line]
+		bufferLine := index.Line - t.indexBase
 
-		if index.Line > len(t.buffer)-1 {
+		if index.Stale || bufferLine < 0 || bufferLine > len(t.buffer)-1 {
 			continue
 		}
 
-		if index.Pos < 0 || index.NextPos > len(t.buffer[index.Line]) {
+		if index.Pos < 0 || index.NextPos > len(t.buffer[bufferLine]) {
 			continue
 		}
 
-		text := t.buffer[index.Line][index.Pos:index.NextPos]
+		text := t.buffer[bufferLine][index.Pos:index.NextPos]
+
+		// Find the search matches in this line.
+		if t.search != nil && index.Line != matchesLine {
+			var ok bool
+			if matches, ok = t.searchMatches[index.Line]; !ok {
+				_, _, _, _, _, strippedStr, _ := t.decompose(t.buffer[bufferLine])
+				matches = t.search.FindAllStringIndex(strippedStr, -1)
+			}
+			searchMatches[index.Line], matchesLine = matches, index.Line
+		}
 
 		foregroundColor := index.ForegroundColor
 		backgroundColor := index.BackgroundColor
 		attributes := index.Attributes
 		regionID := index.Region
+		url := index.URL
 
 		// Process tags.
-		colorTagIndices, colorTags, regionIndices, regions, escapeIndices, strippedText, _ := decomposeString(text, t.dynamicColors, t.regions)
+		colorTagIndices, colorTags, regionIndices, regions, escapeIndices, strippedText, _ := t.decompose(text)
 
 		// Calculate the position of the line.
 		var skip, posX int
@@ -875,13 +2032,14 @@ func (t *TextView) Draw(screen tcell.Screen) {
 		}
 
 		// Print the line.
-		var colorPos, regionPos, escapePos, tagOffset, skipped int
+		var colorPos, regionPos, escapePos, tagOffset, skipped, matchPos int
 		iterateString(strippedText, func(main rune, comb []rune, textPos, textWidth, screenPos, screenWidth int) bool {
 			// Process tags.
 			for {
 				if colorPos < len(colorTags) && textPos+tagOffset >= colorTagIndices[colorPos][0] && textPos+tagOffset < colorTagIndices[colorPos][1] {
 					// Get the color.
 					foregroundColor, backgroundColor, attributes = styleFromTag(foregroundColor, backgroundColor, attributes, colorTags[colorPos])
+					url = urlFromTag(url, colorTags[colorPos])
 					tagOffset += colorTagIndices[colorPos][1] - colorTagIndices[colorPos][0]
 					colorPos++
 				} else if regionPos < len(regionIndices) && textPos+tagOffset >= regionIndices[regionPos][0] && textPos+tagOffset < regionIndices[regionPos][1] {
@@ -908,7 +2066,19 @@ func (t *TextView) Draw(screen tcell.Screen) {
 			// Do we highlight this character?
 			var highlighted bool
 			if len(regionID) > 0 {
-				if _, ok := t.highlights[regionID]; ok {
+				if _, ok := t.highlights[regionID]; ok || regionID == t.hoverRegion {
+					highlighted = true
+				}
+			}
+			for matchPos < len(matches) && matches[matchPos][1] <= index.StrippedPos+textPos {
+				matchPos++
+			}
+			if matchPos < len(matches) && matches[matchPos][0] <= index.StrippedPos+textPos {
+				highlighted = true
+			}
+			if t.selected {
+				position := textViewPosition{Line: index.Line, Pos: index.StrippedPos + textPos}
+				if !position.before(selectFrom) && position.before(selectTo) {
 					highlighted = true
 				}
 			}
@@ -945,6 +2115,19 @@ func (t *TextView) Draw(screen tcell.Screen) {
 				} else {
 					screen.SetContent(x+posX+offset, y+line-t.lineOffset, ' ', nil, style)
 				}
+				if url != "" && linker != nil {
+					linker.SetHyperlink(x+posX+offset, y+line-t.lineOffset, url)
+				}
+			}
+
+			// Remember where regions are.
+			if len(regionID) > 0 {
+				last := len(t.drawnRegions) - 1
+				if last >= 0 && t.drawnRegions[last].ID == regionID && t.drawnRegions[last].Y == y+line-t.lineOffset && t.drawnRegions[last].X+t.drawnRegions[last].Width == x+posX {
+					t.drawnRegions[last].Width += screenWidth
+				} else {
+					t.drawnRegions = append(t.drawnRegions, textViewRegion{X: x + posX, Y: y + line - t.lineOffset, Width: screenWidth, ID: regionID})
+				}
 			}
 
 			// Advance.
@@ -952,21 +2135,38 @@ func (t *TextView) Draw(screen tcell.Screen) {
 			return false
 		})
 	}
+	t.searchMatches = searchMatches
 
 	// If this view is not scrollable, we'll purge the buffer of lines that have
 	// scrolled out of view.
 	if !t.scrollable && t.lineOffset > 0 {
-		t.buffer = t.buffer[t.index[t.lineOffset].Line:]
-		t.index = nil
+		t.removeLines(t.index[t.lineOffset].Line - t.indexBase)
 	}
 }
 
+// ConsumesKey returns whether or not the text view uses the given key itself.
+// This is the case for Tab and Backtab if they move between clickable regions
+// (see SetRegionClickedFunc()) or if a handler was installed with
+// SetDoneFunc().
+func (t *TextView) ConsumesKey(event *tcell.EventKey) bool {
+	return isTabKey(event) && (t.regions && t.regionClicked != nil || t.done != nil)
+}
+
 // InputHandler returns the handler for this primitive.
 func (t *TextView) InputHandler() func(event *tcell.EventKey, setFocus func(p Primitive)) {
 	return t.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p Primitive)) {
 		key := event.Key()
 
+		// Keys go to the search prompt while it is open.
+		if t.searchField != nil {
+			t.searchField.InputHandler()(event, setFocus)
+			return
+		}
+
 		if key == tcell.KeyEscape || key == tcell.KeyEnter || key == tcell.KeyTab || key == tcell.KeyBacktab {
+			if t.regions && t.regionClicked != nil && t.cycleRegions(key) {
+				return
+			}
 			if t.done != nil {
 				t.done(key)
 			}
@@ -977,6 +2177,15 @@ func (t *TextView) InputHandler() func(event *tcell.EventKey, setFocus func(p Pr
 			return
 		}
 
+		// Extend the selection.
+		if t.selectable && event.Modifiers()&tcell.ModShift != 0 {
+			switch key {
+			case tcell.KeyUp, tcell.KeyDown, tcell.KeyLeft, tcell.KeyRight:
+				t.moveSelection(key)
+				return
+			}
+		}
+
 		switch key {
 		case tcell.KeyRune:
 			switch event.Rune() {
@@ -990,12 +2199,19 @@ func (t *TextView) InputHandler() func(event *tcell.EventKey, setFocus func(p Pr
 			case 'j': // Down.
 				t.lineOffset++
 			case 'k': // Up.
-				t.trackEnd = false
-				t.lineOffset--
+				t.scrollUp(1)
 			case 'h': // Left.
 				t.columnOffset--
 			case 'l': // Right.
 				t.columnOffset++
+			case '/', '?': // Search.
+				if t.searchPrompt {
+					t.openSearchPrompt(event.Rune() == '?')
+				}
+			case 'n': // Next match.
+				t.searchMatch(t.searchBackwards)
+			case 'N': // Previous match.
+				t.searchMatch(!t.searchBackwards)
 			}
 		case tcell.KeyHome:
 			t.trackEnd = false
@@ -1005,8 +2221,7 @@ func (t *TextView) InputHandler() func(event *tcell.EventKey, setFocus func(p Pr
 			t.trackEnd = true
 			t.columnOffset = 0
 		case tcell.KeyUp:
-			t.trackEnd = false
-			t.lineOffset--
+			t.scrollUp(1)
 		case tcell.KeyDown:
 			t.lineOffset++
 		case tcell.KeyLeft:
@@ -1016,8 +2231,106 @@ func (t *TextView) InputHandler() func(event *tcell.EventKey, setFocus func(p Pr
 		case tcell.KeyPgDn, tcell.KeyCtrlF:
 			t.lineOffset += t.pageSize
 		case tcell.KeyPgUp, tcell.KeyCtrlB:
-			t.trackEnd = false
-			t.lineOffset -= t.pageSize
+			t.scrollUp(t.pageSize)
 		}
 	})
 }
+
+// MouseHandler returns the mouse handler for this primitive. If the text view
+// is scrollable, the mouse wheel scrolls its contents. If it is selectable,
+// dragging the mouse with the left button held down selects text. Clicks on
+// regions are passed to the handler installed with SetRegionClickedFunc().
+func (t *TextView) MouseHandler() func(event *MouseEvent) bool {
+	return func(event *MouseEvent) bool {
+		if t.regions && t.regionClicked != nil {
+			switch event.Action {
+			case MouseMove, MouseHoverEnter:
+				if regionID := t.regionAt(event.Position()); regionID != t.hoverRegion {
+					t.hoverRegion = regionID
+					return true
+				}
+			case MouseHoverLeave:
+				if t.hoverRegion != "" {
+					t.hoverRegion = ""
+					return true
+				}
+			case MouseClick:
+				if regionID := t.regionAt(event.Position()); regionID != "" && event.Button == tcell.Button1 {
+					t.regionClicked(regionID, event)
+					return true
+				}
+			}
+		}
+
+		if t.selectable && event.Button == tcell.Button1 {
+			switch event.Action {
+			case MouseDown:
+				t.Lock()
+				t.selected = false
+				t.selectionAnchor = t.positionAt(event.X, event.Y)
+				t.Unlock()
+				return true
+			case MouseDragStart, MouseDragMove:
+				t.Lock()
+				// Scroll if the pointer is above or below the text.
+				_, rectY, _, _ := t.GetRect()
+				_, innerY, _, _ := t.GetInnerRect()
+				if row := event.Y - innerY + rectY; t.scrollable && row < 0 && t.lineOffset > 0 {
+					t.trackEnd = false
+					t.wrapAbove(1)
+					t.lineOffset--
+				} else if t.scrollable && row >= t.pageSize {
+					t.lineOffset++
+				}
+				t.selected = true
+				t.selectionCursor = t.positionAt(event.X, event.Y)
+				t.Unlock()
+				return true
+			case MouseDragEnd:
+				if text := t.GetSelection(); text != "" && t.copied != nil {
+					t.copied(text)
+				}
+				return true
+			}
+		}
+
+		if !t.scrollable {
+			return false
+		}
+
+		switch event.Action {
+		case MouseScrollUp:
+			t.scrollUp(1)
+		case MouseScrollDown:
+			t.lineOffset++
+		case MouseScrollLeft:
+			t.columnOffset--
+		case MouseScrollRight:
+			t.columnOffset++
+		default:
+			return false
+		}
+
+		return true
+	}
+}
+
+// CopyHandler returns the handler which provides the selected text (see
+// GetSelection()) or, if no text is selected, the text of the highlighted
+// regions of this text view for copying to the clipboard (see Highlight() and
+// GetRegionText()). The texts of multiple regions are separated by line breaks,
+// in the order of their region IDs.
+func (t *TextView) CopyHandler() func() string {
+	return func() string {
+		if text := t.GetSelection(); text != "" {
+			return text
+		}
+		regionIDs := t.GetHighlights()
+		sort.Strings(regionIDs)
+		texts := make([]string, 0, len(regionIDs))
+		for _, regionID := range regionIDs {
+			texts = append(texts, t.GetRegionText(regionID))
+		}
+		return strings.Join(texts, "\n")
+	}
+}
@@ -0,0 +1,384 @@
+package tview
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/tcell"
+)
+
+// drawTextView draws the text view onto a new simulation screen of the given
+// size and returns the screen.
+func drawTextView(t *TextView, width, height int) tcell.SimulationScreen {
+	screen := tcell.NewSimulationScreen("")
+	screen.Init()
+	screen.SetSize(width, height)
+	t.SetRect(0, 0, width, height)
+	t.Draw(screen)
+	return screen
+}
+
+// screenRow returns the text and the styles of the given screen row.
+func screenRow(screen tcell.SimulationScreen, row int) (text string, styles []tcell.Style) {
+	width, _ := screen.Size()
+	for x := 0; x < width; x++ {
+		main, _, style, _ := screen.GetContent(x, row)
+		text += string(main)
+		styles = append(styles, style)
+	}
+	return
+}
+
+func TestTextViewTrim(t *testing.T) {
+	const text = "[red]zero [::b]bold[\"a\"]region\n" +
+		"one [\"b\"]still [blue]b\n" +
+		"two\n" +
+		"[green]three[\"\"] done"
+
+	// Draw the last two lines without trimming.
+	full := NewTextView().SetDynamicColors(true).SetRegions(true).Highlight("b")
+	fmt.Fprint(full, text)
+	full.ScrollTo(2, 0)
+	expected := drawTextView(full, 20, 2)
+
+	regionText := map[int]string{2: "two\nthree", 3: "still b\ntwo\nthree"}
+	for _, maxLines := range []int{2, 3} {
+		for _, indexed := range []bool{false, true} {
+			trimmed := NewTextView().SetDynamicColors(true).SetRegions(true).SetMaxLines(maxLines).Highlight("b")
+			split := len(text) - len("[green]three[\"\"] done")
+			fmt.Fprint(trimmed, text[:split])
+			if indexed {
+				// Index the first lines before the last one is added.
+				drawTextView(trimmed, 20, 2)
+			}
+			fmt.Fprint(trimmed, text[split:])
+			trimmed.ScrollToEnd()
+			screen := drawTextView(trimmed, 20, 2)
+
+			// The state carried over from removed lines doesn't change the text.
+			lines := strings.Split(text, "\n")
+			if got := trimmed.GetText(false); got != strings.Join(lines[len(lines)-maxLines:], "\n")+"\n" {
+				t.Errorf("max lines %d, indexed %t: text is %q", maxLines, indexed, got)
+			}
+			if got := trimmed.GetRegionText("b"); got != regionText[maxLines] {
+				t.Errorf("max lines %d, indexed %t: region text is %q", maxLines, indexed, got)
+			}
+
+			for row := 0; row < 2; row++ {
+				expectedText, expectedStyles := screenRow(expected, row)
+				text, styles := screenRow(screen, row)
+				if text != expectedText {
+					t.Errorf("max lines %d, indexed %t: row %d is %q, expected %q", maxLines, indexed, row, text, expectedText)
+					continue
+				}
+				for x := range styles {
+					if styles[x] != expectedStyles[x] {
+						t.Errorf("max lines %d, indexed %t: style of %q differs at row %d, column %d", maxLines, indexed, text, row, x)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestTextViewPurge(t *testing.T) {
+	textView := NewTextView().SetScrollable(false).SetRegions(true).Highlight("b")
+	fmt.Fprint(textView, "zero\n[\"b\"]one\ntwo\nthree")
+	textView.selected = true
+	textView.selectionAnchor = textViewPosition{Line: 2, Pos: 1}
+	textView.selectionCursor = textViewPosition{Line: 3, Pos: 1}
+
+	// Lines scrolled out of view are removed from the buffer.
+	drawTextView(textView, 10, 2)
+	if text := textView.GetText(false); text != "two\nthree\n" {
+		t.Errorf("text is %q after purging", text)
+	}
+	if textView.indexBase != 2 {
+		t.Errorf("index base is %d after purging, expected 2", textView.indexBase)
+	}
+
+	// Positions in the remaining lines are still valid.
+	if selection := textView.GetSelection(); selection != "wo\nth" {
+		t.Errorf("selection is %q after purging", selection)
+	}
+
+	// The highlighted region continues.
+	fmt.Fprint(textView, "\nfour")
+	screen := drawTextView(textView, 10, 2)
+	for row := 0; row < 2; row++ {
+		_, styles := screenRow(screen, row)
+		if _, background, _ := styles[0].Decompose(); background == textView.backgroundColor {
+			t.Errorf("row %d is not highlighted", row)
+		}
+	}
+	if text := textView.GetRegionText("b"); text != "three\nfour\n" {
+		t.Errorf("region text is %q after purging", text)
+	}
+}
+
+// sampleText returns the given number of lines of text with color tags and
+// regions which are wrapped when the text view is narrow.
+func sampleText(lines int) string {
+	var text string
+	for line := 0; line < lines; line++ {
+		text += fmt.Sprintf(`Line [red]%d[white] has ["r%d"]a region[""] and [::b]some words to wrap[::-].`+"\n", line, line)
+	}
+	return text
+}
+
+// compareScreens reports an error if the text of the two screens differs.
+func compareScreens(t *testing.T, name string, screen, expected tcell.SimulationScreen) {
+	t.Helper()
+	_, height := expected.Size()
+	for row := 0; row < height; row++ {
+		expectedText, _ := screenRow(expected, row)
+		if text, _ := screenRow(screen, row); text != expectedText {
+			t.Errorf("%s: row %d is %q, expected %q", name, row, text, expectedText)
+		}
+	}
+}
+
+func TestTextViewIncrementalIndex(t *testing.T) {
+	text := sampleText(20)
+	textView := NewTextView().SetDynamicColors(true).SetRegions(true).SetWordWrap(true)
+	for start := 0; start < len(text); start += 37 {
+		end := start + 37
+		if end > len(text) {
+			end = len(text)
+		}
+		fmt.Fprint(textView, text[start:end])
+		drawTextView(textView, 30, 5)
+	}
+
+	expected := NewTextView().SetDynamicColors(true).SetRegions(true).SetWordWrap(true)
+	fmt.Fprint(expected, text)
+	drawTextView(expected, 30, 5)
+
+	if len(textView.index) != len(expected.index) {
+		t.Fatalf("index has %d lines, expected %d", len(textView.index), len(expected.index))
+	}
+	for line := range expected.index {
+		if *textView.index[line] != *expected.index[line] {
+			t.Errorf("index line %d is %+v, expected %+v", line, *textView.index[line], *expected.index[line])
+		}
+	}
+}
+
+func TestTextViewLazyRewrap(t *testing.T) {
+	textView := NewTextView().SetDynamicColors(true).SetRegions(true)
+	fmt.Fprint(textView, sampleText(1000))
+	textView.ScrollTo(500, 0)
+	drawTextView(textView, 40, 5)
+
+	// Only the visible lines are wrapped after resizing.
+	screen := drawTextView(textView, 20, 5)
+	var wrapped int
+	for _, line := range textView.index {
+		if !line.Stale {
+			wrapped++
+		}
+	}
+	if wrapped > 20 {
+		t.Errorf("%d index lines were wrapped after resizing", wrapped)
+	}
+	if text, _ := screenRow(screen, 0); text != "Line 250 has a regio" {
+		t.Errorf("first row is %q after resizing", text)
+	}
+
+	// The scroll offset counts wrapped rows without wrapping them.
+	if row, _ := textView.GetScrollOffset(); row != 750 {
+		t.Errorf("scroll offset is %d after resizing, expected 750", row)
+	}
+	for _, line := range textView.index {
+		if !line.Stale {
+			wrapped--
+		}
+	}
+	if wrapped != 0 {
+		t.Errorf("getting the scroll offset wrapped %d index lines", -wrapped)
+	}
+
+	// Scrolling works as if all lines were wrapped.
+	expected := NewTextView().SetDynamicColors(true).SetRegions(true)
+	fmt.Fprint(expected, sampleText(1000))
+	expectedScreen := drawTextView(expected, 20, 5)
+	for _, key := range []tcell.Key{tcell.KeyPgUp, tcell.KeyUp, tcell.KeyPgDn, tcell.KeyEnd, tcell.KeyPgUp, tcell.KeyPgUp} {
+		event := tcell.NewEventKey(key, 0, tcell.ModNone)
+		if key != tcell.KeyEnd && key != tcell.KeyPgDn {
+			// Start at the same position.
+			row, _ := textView.GetScrollOffset()
+			expected.ScrollTo(row, 0)
+			expectedScreen = drawTextView(expected, 20, 5)
+			compareScreens(t, "before key", screen, expectedScreen)
+		}
+		textView.InputHandler()(event, nil)
+		expected.InputHandler()(event, nil)
+		screen = drawTextView(textView, 20, 5)
+		expectedScreen = drawTextView(expected, 20, 5)
+		compareScreens(t, tcell.KeyNames[key], screen, expectedScreen)
+		row, _ := textView.GetScrollOffset()
+		if expectedRow, _ := expected.GetScrollOffset(); row != expectedRow {
+			t.Errorf("%s: scrolled to row %d, expected %d", tcell.KeyNames[key], row, expectedRow)
+		}
+	}
+}
+
+func TestTextViewScrollConcurrently(t *testing.T) {
+	textView := NewTextView().SetMaxLines(100)
+	fmt.Fprint(textView, sampleText(100))
+	drawTextView(textView, 20, 5)
+
+	// Scrolling doesn't race with writing and trimming.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for line := 0; line < 100; line++ {
+			fmt.Fprintln(textView, "Another line which is wrapped.")
+		}
+	}()
+	for row := 0; row < 100; row++ {
+		textView.ScrollTo(row, 0)
+		textView.GetScrollOffset()
+	}
+	<-done
+}
+
+func TestTextViewScrollToHighlight(t *testing.T) {
+	textView := NewTextView().SetDynamicColors(true).SetRegions(true)
+	fmt.Fprint(textView, sampleText(1000))
+	drawTextView(textView, 40, 5)
+	drawTextView(textView, 20, 5)
+
+	textView.Highlight("r700").ScrollToHighlight()
+	screen := drawTextView(textView, 20, 5)
+	expected := NewTextView().SetDynamicColors(true).SetRegions(true)
+	fmt.Fprint(expected, sampleText(1000))
+	drawTextView(expected, 20, 5)
+	expected.Highlight("r700").ScrollToHighlight()
+	compareScreens(t, "highlight", screen, drawTextView(expected, 20, 5))
+	if text, _ := screenRow(screen, 3); text != "Line 700 has a regio" {
+		t.Errorf("highlighted line is not in view, row 3 is %q", text)
+	}
+}
+
+func BenchmarkTextViewWrite(b *testing.B) {
+	for _, wrap := range []bool{true, false} {
+		name := "nowrap"
+		if wrap {
+			name = "wrap"
+		}
+		b.Run(name, func(b *testing.B) {
+			textView := NewTextView().SetDynamicColors(true).SetRegions(true).SetWrap(wrap)
+			fmt.Fprint(textView, sampleText(10000))
+			screen := tcell.NewSimulationScreen("")
+			screen.Init()
+			screen.SetSize(40, 20)
+			textView.SetRect(0, 0, 40, 20)
+			textView.Draw(screen)
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				fmt.Fprintf(textView, "Line [red]%d[white] is new and has [::b]some words to wrap[::-].\n", n)
+				textView.Draw(screen)
+			}
+		})
+	}
+}
+
+func TestTextViewSearchMatches(t *testing.T) {
+	textView := NewTextView().SetDynamicColors(true)
+	fmt.Fprint(textView, "one [red]two\ntwo")
+	if err := textView.SetSearch("two", false); err != nil {
+		t.Fatal(err)
+	}
+
+	// highlighted returns the highlighted columns of the given row.
+	highlighted := func(screen tcell.SimulationScreen, row int) (columns string) {
+		_, styles := screenRow(screen, row)
+		for x, style := range styles {
+			if _, background, _ := style.Decompose(); background != textView.backgroundColor {
+				columns += fmt.Sprint(x)
+			}
+		}
+		return
+	}
+
+	screen := drawTextView(textView, 10, 3)
+	if columns := highlighted(screen, 0) + "," + highlighted(screen, 1); columns != "456,012" {
+		t.Errorf("highlighted columns are %q", columns)
+	}
+	if len(textView.searchMatches) != 2 {
+		t.Errorf("matches of %d lines are cached, expected 2", len(textView.searchMatches))
+	}
+
+	// Text added to the last line is searched again.
+	fmt.Fprint(textView, " two\nthree")
+	screen = drawTextView(textView, 10, 3)
+	if columns := highlighted(screen, 1) + "," + highlighted(screen, 2); columns != "012456," {
+		t.Errorf("highlighted columns are %q after writing", columns)
+	}
+
+	// A new search replaces the cached matches.
+	textView.SetSearch("three", false)
+	screen = drawTextView(textView, 10, 3)
+	if columns := highlighted(screen, 0) + highlighted(screen, 1) + "," + highlighted(screen, 2); columns != ",01234" {
+		t.Errorf("highlighted columns are %q after changing the search", columns)
+	}
+}
+
+// linkScreen is a simulation screen which records hyperlinks.
+type linkScreen struct {
+	tcell.SimulationScreen
+	links map[[2]int]string
+}
+
+func (s *linkScreen) SetHyperlink(x, y int, url string) {
+	s.links[[2]int{x, y}] = url
+}
+
+// drawLinks draws a text view with the given text onto a screen which records
+// hyperlinks and returns the text of the first row and the hyperlinks.
+func drawLinks(text string, hyperlinks bool) (string, map[[2]int]string) {
+	textView := NewTextView().SetDynamicColors(true).SetHyperlinks(hyperlinks)
+	fmt.Fprint(textView, text)
+	screen := &linkScreen{SimulationScreen: tcell.NewSimulationScreen(""), links: make(map[[2]int]string)}
+	screen.Init()
+	screen.SetSize(30, 1)
+	textView.SetRect(0, 0, 30, 1)
+	textView.Draw(screen)
+	row, _ := screenRow(screen.SimulationScreen, 0)
+	return row, screen.links
+}
+
+func TestTextViewHyperlinks(t *testing.T) {
+	const text = "[red:::https://example.com]link[:::-] text"
+	if row, links := drawLinks(text, true); row != "link text                     " {
+		t.Errorf("row is %q with hyperlinks", row)
+	} else if len(links) != 4 || links[[2]int{0, 0}] != "https://example.com" {
+		t.Errorf("hyperlinks are %v", links)
+	}
+	if row, links := drawLinks(text, false); row != "[red:::https://example.com]lin" {
+		t.Errorf("row is %q without hyperlinks", row)
+	} else if len(links) != 0 {
+		t.Errorf("hyperlinks are %v without hyperlinks", links)
+	}
+
+	// Hyperlinks end with an empty URL field or "-" only.
+	for text, length := range map[string]int{
+		"[:::https://example.com]link[:::] text":     4,
+		"[:::https://example.com]link[:::-] text":    4,
+		"[:::https://example.com]link[red] text":     9,
+		"[:::https://example.com]link[red::b:] text": 4,
+		"[:::https://example.com]link[::b] text":     9,
+	} {
+		if _, links := drawLinks(text, true); len(links) != length {
+			t.Errorf("%q has %d linked cells, expected %d", text, len(links), length)
+		}
+	}
+
+	// Other primitives don't recognize hyperlinks.
+	if width := TaggedStringWidth("[red]a[:::https://example.com]b"); width != 26 {
+		t.Errorf("tagged string width is %d", width)
+	}
+}
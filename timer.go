@@ -0,0 +1,285 @@
+package tview
+
+import (
+	"math"
+	"time"
+
+	"github.com/diamondburned/tcell"
+)
+
+// Timer is a function scheduled with Application.AfterFunc(),
+// Application.Every(), or Application.Animate(). Timers are driven by the
+// application's event loop, which checks them on every tick of RefreshRate.
+// Their functions are therefore called on the event loop, where they may access
+// primitives directly, and the screen is redrawn afterwards.
+//
+// A timer may be tied to a primitive with SetPrimitive(). It is then paused
+// while the primitive is not visible.
+type Timer struct {
+	app *Application
+
+	// When the function is due next.
+	due time.Time
+
+	// The time between two calls of a repeating timer or 0 if the timer
+	// doesn't repeat at a fixed interval.
+	interval time.Duration
+
+	// The function which is called when the timer is due. It returns true if
+	// it is to be called again.
+	run func(now time.Time) bool
+
+	// The primitive whose visibility the timer is tied to or nil.
+	primitive Primitive
+
+	// Whether or not the timer was stopped or has finished.
+	stopped bool
+}
+
+// AfterFunc calls the given function on the application's event loop once the
+// given duration has elapsed, or at the next tick of RefreshRate after that.
+// This function may be called from any goroutine.
+func (a *Application) AfterFunc(d time.Duration, f func()) *Timer {
+	return a.addTimer(d, 0, func(time.Time) bool {
+		f()
+		return false
+	})
+}
+
+// Every calls the given function on the application's event loop repeatedly,
+// every time the given duration has elapsed, e.g. to advance a spinner. It is
+// called at most once per tick of RefreshRate. Calls missed while the
+// application was busy are not caught up on. This function may be called from
+// any goroutine.
+func (a *Application) Every(d time.Duration, f func()) *Timer {
+	if d <= 0 {
+		d = time.Nanosecond
+	}
+	return a.addTimer(d, d, func(time.Time) bool {
+		f()
+		return true
+	})
+}
+
+// Animate calls the given function on the application's event loop on every
+// tick of RefreshRate during the given duration, e.g. to fade colors (see
+// BlendColors()) or to scroll smoothly. The function receives the progress of
+// the animation, from 0 to 1, as mapped by the given easing function (see
+// EaseInOutQuad() and others, nil for linear progress). The last call always
+// receives 1:
+//
+//   from, _ := textView.GetScrollOffset()
+//   app.Animate(300*time.Millisecond, tview.EaseOutCubic, func(progress float64) {
+//     textView.ScrollTo(from+int(progress*float64(to-from)), 0)
+//   }).SetPrimitive(textView)
+//
+// This function may be called from any goroutine.
+func (a *Application) Animate(duration time.Duration, easing func(t float64) float64, f func(progress float64)) *Timer {
+	if easing == nil {
+		easing = EaseLinear
+	}
+	start := time.Now()
+	return a.addTimer(0, 0, func(now time.Time) bool {
+		t := 1.0
+		if duration > 0 {
+			t = math.Min(float64(now.Sub(start))/float64(duration), 1)
+		}
+		if t < 1 {
+			f(easing(t))
+			return true
+		}
+		f(1)
+		return false
+	})
+}
+
+// Stop stops the timer. It returns true if the timer was stopped by this call
+// and false if it had already been stopped or, unless it repeats, its function
+// had already been called.
+func (t *Timer) Stop() bool {
+	t.app.Lock()
+	defer t.app.Unlock()
+
+	if t.stopped {
+		return false
+	}
+	t.app.removeTimer(t)
+	return true
+}
+
+// SetPrimitive ties the timer to the given primitive. While the primitive is
+// not visible (i.e. not part of the tree of primitives starting at the
+// application's root primitive, see Container, or without any space on the
+// screen), the timer is paused: Repeating timers and animations skip their
+// calls, other timers are called once the primitive is visible again. Provide
+// nil to untie the timer.
+//
+// Timers are not stopped when their primitive is removed. Call Stop() when the
+// timer is no longer needed.
+func (t *Timer) SetPrimitive(p Primitive) *Timer {
+	t.app.Lock()
+	defer t.app.Unlock()
+
+	t.primitive = p
+	return t
+}
+
+// GetPrimitive returns the primitive set with SetPrimitive() or nil if the
+// timer is not tied to a primitive.
+func (t *Timer) GetPrimitive() Primitive {
+	t.app.RLock()
+	defer t.app.RUnlock()
+
+	return t.primitive
+}
+
+// addTimer schedules a new timer.
+func (a *Application) addTimer(d, interval time.Duration, run func(now time.Time) bool) *Timer {
+	timer := &Timer{
+		app:      a,
+		due:      time.Now().Add(d),
+		interval: interval,
+		run:      run,
+	}
+
+	a.Lock()
+	a.timers = append(a.timers, timer)
+	a.Unlock()
+
+	return timer
+}
+
+// removeTimer removes the given timer from the list of scheduled timers. The
+// application must be locked.
+func (a *Application) removeTimer(timer *Timer) {
+	timer.stopped = true
+	for index, t := range a.timers {
+		if t == timer {
+			a.timers = append(a.timers[:index], a.timers[index+1:]...)
+			break
+		}
+	}
+}
+
+// runTimers calls the functions of all timers which are due. It returns true if
+// any function was called.
+func (a *Application) runTimers(now time.Time) (ran bool) {
+	a.RLock()
+	root := a.root
+	var due []*Timer
+	for _, timer := range a.timers {
+		if !now.Before(timer.due) {
+			due = append(due, timer)
+		}
+	}
+	a.RUnlock()
+
+	for _, timer := range due {
+		a.RLock()
+		stopped, primitive := timer.stopped, timer.primitive
+		a.RUnlock()
+		if stopped {
+			continue // Stopped by a previous function.
+		}
+
+		again := true
+		if primitive == nil || isVisible(root, primitive) {
+			again = timer.run(now)
+			ran = true
+		} else if timer.interval == 0 {
+			continue // Wait until the primitive is visible.
+		}
+
+		a.Lock()
+		if !again {
+			a.removeTimer(timer)
+		} else if !timer.stopped {
+			timer.due = timer.due.Add(timer.interval)
+			if timer.due.Before(now) {
+				timer.due = now.Add(timer.interval)
+			}
+		}
+		a.Unlock()
+	}
+
+	return
+}
+
+// isVisible returns true if the given primitive is part of the tree of
+// primitives starting at the given root primitive and occupies space on the
+// screen.
+func isVisible(root, p Primitive) bool {
+	if _, _, width, height := p.GetRect(); width <= 0 || height <= 0 {
+		return false
+	}
+	return getPrimitivePath(root, p) != nil
+}
+
+// Easing functions which may be passed to Application.Animate(). They map the
+// progress of an animation over time, from 0 to 1, to the progress of the
+// animated value. "In" functions start slowly, "Out" functions end slowly.
+
+// EaseLinear returns t unchanged.
+func EaseLinear(t float64) float64 {
+	return t
+}
+
+// EaseInQuad accelerates from zero velocity.
+func EaseInQuad(t float64) float64 {
+	return t * t
+}
+
+// EaseOutQuad decelerates to zero velocity.
+func EaseOutQuad(t float64) float64 {
+	return t * (2 - t)
+}
+
+// EaseInOutQuad accelerates until halfway, then decelerates.
+func EaseInOutQuad(t float64) float64 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
+
+// EaseInCubic accelerates from zero velocity, more strongly than EaseInQuad().
+func EaseInCubic(t float64) float64 {
+	return t * t * t
+}
+
+// EaseOutCubic decelerates to zero velocity, more strongly than
+// EaseOutQuad().
+func EaseOutCubic(t float64) float64 {
+	t--
+	return t*t*t + 1
+}
+
+// EaseInOutCubic accelerates until halfway, then decelerates, more strongly
+// than EaseInOutQuad().
+func EaseInOutCubic(t float64) float64 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	t = 2*t - 2
+	return t*t*t/2 + 1
+}
+
+// BlendColors returns the color between the two given colors at the given
+// position, from 0 (the first color) to 1 (the second color), e.g. to fade
+// text in an animation (see Application.Animate()). If one of the colors is the
+// terminal's default color, which can't be blended, the first color is
+// returned for positions below 1.
+func BlendColors(from, to tcell.Color, t float64) tcell.Color {
+	if t >= 1 {
+		return to
+	}
+	if t <= 0 || from == tcell.ColorDefault || to == tcell.ColorDefault {
+		return from
+	}
+	r1, g1, b1 := from.RGB()
+	r2, g2, b2 := to.RGB()
+	blend := func(c1, c2 int32) int32 {
+		return c1 + int32(math.Round(float64(c2-c1)*t))
+	}
+	return tcell.NewRGBColor(blend(r1, r2), blend(g1, g2), blend(b1, b2))
+}
@@ -1,8 +1,6 @@
 package tview
 
 import (
-	"time"
-
 	"github.com/diamondburned/tcell"
 )
 
@@ -17,12 +15,6 @@ const (
 	treePageDown
 )
 
-var (
-	// DoubleClickDuration is used to determine the time between
-	// two clicks for a double click
-	DoubleClickDuration = 250 * time.Millisecond
-)
-
 // TreeNode represents one node in a tree view.
 type TreeNode struct {
 	// The reference object.
@@ -291,9 +283,12 @@ type TreeView struct {
 	// The visible nodes, top-down, as set by process(). Broken.
 	nodes []*TreeNode
 
-	mousefn       func(*tcell.EventMouse) bool
-	lastClickTime time.Time
-	singleClick   bool
+	mousefn     func(*tcell.EventMouse) bool
+	singleClick bool
+
+	// The last raw mouse event passed to mousefn. A raw event may result in
+	// several mouse actions but mousefn is called only once for it.
+	lastMouseEvent *tcell.EventMouse
 }
 
 // NewTreeView returns a new tree view.
@@ -391,7 +386,8 @@ func (t *TreeView) SetSelectedFunc(handler func(node *TreeNode)) *TreeView {
 	return t
 }
 
-// SetMouseFunc sets the mouse function
+// SetMouseFunc sets the mouse function. It receives the raw mouse events of the
+// terminal which reach the tree view and returns true if it consumed them.
 func (t *TreeView) SetMouseFunc(fn func(*tcell.EventMouse) bool) *TreeView {
 	t.mousefn = fn
 	return t
@@ -670,6 +666,12 @@ func (t *TreeView) Draw(screen tcell.Screen) {
 	}
 }
 
+// ConsumesKey returns whether or not the tree view uses the given key itself.
+// This is the case for Tab and Backtab, which move between the nodes.
+func (t *TreeView) ConsumesKey(event *tcell.EventKey) bool {
+	return isTabKey(event)
+}
+
 // InputHandler returns the handler for this primitive.
 func (t *TreeView) InputHandler() func(event *tcell.EventKey, setFocus func(p Primitive)) {
 	return t.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p Primitive)) {
@@ -715,45 +717,67 @@ func (t *TreeView) InputHandler() func(event *tcell.EventKey, setFocus func(p Pr
 }
 
 // MouseHandler returns a mouse handler
-func (t *TreeView) MouseHandler() func(*tcell.EventMouse) bool {
-	return func(ev *tcell.EventMouse) (b bool) {
+func (t *TreeView) MouseHandler() func(*MouseEvent) bool {
+	return func(ev *MouseEvent) (b bool) {
 		defer func() {
-			if t.mousefn != nil && t.mousefn(ev) {
-				b = true
+			if t.mousefn != nil && ev.EventMouse != t.lastMouseEvent {
+				t.lastMouseEvent = ev.EventMouse
+				if t.mousefn(ev.EventMouse) {
+					b = true
+				}
 			}
 		}()
 
-		switch ev.Buttons() {
-		case tcell.Button1:
-			_, y := ev.Position()
-			n := t.offsetY + y - 1
+		// Triggers the "selected" callbacks of the current node.
+		selectNode := func() {
+			if t.currentNode != nil {
+				if t.selected != nil {
+					t.selected(t.currentNode)
+				}
+
+				if t.currentNode.selected != nil {
+					t.currentNode.selected()
+				}
+			}
+		}
+
+		switch ev.Action {
+		case MouseClick:
+			if ev.Button != tcell.Button1 {
+				return false
+			}
+
+			x, y := ev.Position()
+			if !t.InInnerRect(x, y) {
+				return false
+			}
+			_, rectY, _, _ := t.GetInnerRect()
+			n := t.offsetY + y - rectY
 
 			if n >= len(t.nodes) || n < 0 {
 				return false
 			}
 
 			t.SetCurrentNode(t.nodes[n])
-			if ev.When().Sub(t.lastClickTime) <= DoubleClickDuration || t.singleClick {
-				if t.currentNode != nil {
-					if t.selected != nil {
-						t.selected(t.currentNode)
-					}
-
-					if t.currentNode.selected != nil {
-						t.currentNode.selected()
-					}
-				}
+			if t.singleClick {
+				selectNode()
 			}
 
-			t.lastClickTime = ev.When()
+			return true
+
+		case MouseDoubleClick:
+			if ev.Button != tcell.Button1 || t.singleClick {
+				return false
+			}
 
+			selectNode()
 			return true
 
-		case tcell.WheelUp:
+		case MouseScrollUp:
 			t.movement = treeUp
 			return true
 
-		case tcell.WheelDown:
+		case MouseScrollDown:
 			t.movement = treeDown
 			return true
 		}
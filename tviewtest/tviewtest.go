@@ -0,0 +1,384 @@
+// Package tviewtest runs tview applications against a simulated screen so that
+// they can be tested without a terminal.
+//
+// A Harness starts an application on a tcell.SimulationScreen. Key, mouse,
+// paste, and resize events are injected synchronously: Each injection returns
+// only after the application has processed the event and redrawn the screen.
+// The screen content can then be inspected as text, as styles, or compared to
+// a golden file:
+//
+//   func TestInputField(t *testing.T) {
+//       input := tview.NewInputField().SetLabel("Name: ")
+//       h := tviewtest.New(input, 40, 1)
+//       defer h.Close()
+//
+//       h.Type("Alice")
+//       tviewtest.Golden(t, "input", h.Snapshot())
+//   }
+//
+// Golden files are stored in the "testdata" directory. They are written
+// instead of compared when the TVIEWTEST_UPDATE environment variable is set.
+//
+// Each Harness runs its own application so tests using harnesses may run in
+// parallel.
+package tviewtest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/tcell"
+	"github.com/diamondburned/tview/v2"
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// SyncTimeout is the maximum time a Harness waits for the application to
+// process an injected event.
+var SyncTimeout = 5 * time.Second
+
+// UpdateEnv is the name of the environment variable which causes Golden() to
+// write golden files instead of comparing them.
+const UpdateEnv = "TVIEWTEST_UPDATE"
+
+// Harness runs an application on a simulated screen.
+type Harness struct {
+	// The application under test.
+	App *tview.Application
+
+	// The simulated screen the application draws to.
+	Screen tcell.SimulationScreen
+
+	// Receives the return value of Application.Run().
+	done chan error
+}
+
+// New starts an application showing the given root primitive on a simulated
+// screen of the given size and waits until it is drawn for the first time. The
+// root primitive fills the screen and receives the focus. The application
+// draws synchronously (see Application.SetSynchronousDraw()). Call Close() when
+// done.
+func New(root tview.Primitive, width, height int) *Harness {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		panic(err)
+	}
+	screen.SetSize(width, height)
+
+	app := tview.NewApplication()
+	app.SetScreen(screen)
+	app.SetRoot(root, true)
+	app.SetSynchronousDraw(true)
+
+	h := &Harness{
+		App:    app,
+		Screen: screen,
+		done:   make(chan error, 1),
+	}
+	go func() {
+		h.done <- app.Run()
+	}()
+	h.Sync()
+
+	return h
+}
+
+// Close stops the application and waits for it to shut down.
+func (h *Harness) Close() error {
+	h.App.Stop()
+	return <-h.done
+}
+
+// Sync waits until the application has run all updates queued so far (see
+// Application.QueueUpdate()) and has redrawn the screen. It panics if this takes
+// longer than SyncTimeout.
+func (h *Harness) Sync() {
+	h.wait(func() {
+		h.App.QueueUpdateWait(func() {})
+		h.App.WaitForFrame()
+	})
+}
+
+// Inject sends an arbitrary event to the application and waits for it to be
+// processed and for the screen to be redrawn.
+func (h *Harness) Inject(event tcell.Event) {
+	h.wait(func() {
+		h.App.QueueEventWait(event)
+		h.App.WaitForFrame()
+	})
+}
+
+// wait calls the given function and waits for it to return. It panics if this
+// takes longer than SyncTimeout.
+func (h *Harness) wait(f func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(SyncTimeout):
+		panic("tviewtest: application did not process events in time")
+	}
+}
+
+// Key sends a key event to the application and waits for it to be processed.
+func (h *Harness) Key(key tcell.Key, r rune, mod tcell.ModMask) {
+	h.Inject(tcell.NewEventKey(key, r, mod))
+}
+
+// Type sends the runes of the given text to the application as key events,
+// one at a time.
+func (h *Harness) Type(text string) {
+	for _, r := range text {
+		h.Key(tcell.KeyRune, r, tcell.ModNone)
+	}
+}
+
+// Mouse sends a raw mouse event to the application and waits for it to be
+// processed. "buttons" are the buttons held down at the time of the event.
+func (h *Harness) Mouse(x, y int, buttons tcell.ButtonMask, mod tcell.ModMask) {
+	h.Inject(tcell.NewEventMouse(x, y, buttons, mod))
+}
+
+// Click presses and releases the given mouse button at the given position.
+func (h *Harness) Click(x, y int, button tcell.ButtonMask) {
+	h.Mouse(x, y, button, tcell.ModNone)
+	h.Mouse(x, y, tcell.ButtonNone, tcell.ModNone)
+}
+
+// Paste sends the given text to the application as a single paste event.
+func (h *Harness) Paste(text string) {
+	h.Inject(tview.NewEventPaste(text))
+}
+
+// Resize changes the size of the simulated screen and waits for the
+// application to redraw it.
+func (h *Harness) Resize(width, height int) {
+	h.Screen.SetSize(width, height)
+	h.Inject(tcell.NewEventResize(width, height))
+}
+
+// Cell is a single cell of the screen.
+type Cell struct {
+	// The runes displayed in this cell, the main rune first. This is empty for
+	// the second cell of a wide character.
+	Runes []rune
+
+	// The style of the cell.
+	Style tcell.Style
+}
+
+// Cells returns the content of the screen, one slice of cells per row.
+func (h *Harness) Cells() [][]Cell {
+	contents, width, height := h.Screen.GetContents()
+	rows := make([][]Cell, height)
+	for y := range rows {
+		rows[y] = make([]Cell, width)
+		for x := 0; x < width; x++ {
+			cell := contents[y*width+x]
+			rows[y][x] = Cell{Runes: cell.Runes, Style: cell.Style}
+			if len(cell.Runes) > 0 && runewidth.RuneWidth(cell.Runes[0]) == 2 && x+1 < width {
+				x++
+				rows[y][x] = Cell{Style: contents[y*width+x].Style}
+			}
+		}
+	}
+	return rows
+}
+
+// Text returns the text on the screen, one line per row. Trailing spaces are
+// removed from each line.
+func (h *Harness) Text() string {
+	var buffer strings.Builder
+	for y, row := range h.Cells() {
+		if y > 0 {
+			buffer.WriteByte('\n')
+		}
+		var line strings.Builder
+		for _, cell := range row {
+			if len(cell.Runes) == 0 {
+				continue
+			}
+			if cell.Runes[0] == 0 {
+				line.WriteByte(' ')
+				continue
+			}
+			line.WriteString(string(cell.Runes))
+		}
+		buffer.WriteString(strings.TrimRight(line.String(), " "))
+	}
+	return buffer.String()
+}
+
+// Styles returns a map of the styles on the screen. Each cell is represented by
+// a letter which identifies its style. The map is followed by a legend which
+// describes the style of each letter.
+func (h *Harness) Styles() string {
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	var (
+		buffer strings.Builder
+		styles []tcell.Style
+		index  = make(map[tcell.Style]int)
+	)
+	for _, row := range h.Cells() {
+		for _, cell := range row {
+			i, ok := index[cell.Style]
+			if !ok {
+				i = len(styles)
+				index[cell.Style] = i
+				styles = append(styles, cell.Style)
+			}
+			if i < len(letters) {
+				buffer.WriteByte(letters[i])
+			} else {
+				buffer.WriteByte('?')
+			}
+		}
+		buffer.WriteByte('\n')
+	}
+
+	buffer.WriteByte('\n')
+	for i, style := range styles {
+		letter := byte('?')
+		if i < len(letters) {
+			letter = letters[i]
+		}
+		fmt.Fprintf(&buffer, "%c: %s\n", letter, describeStyle(style))
+	}
+
+	return buffer.String()
+}
+
+// Snapshot returns the text and the styles of the screen as well as the cursor
+// position, suitable for comparison with a golden file.
+func (h *Harness) Snapshot() string {
+	cursor := "hidden"
+	if x, y, visible := h.Screen.GetCursor(); visible {
+		cursor = fmt.Sprintf("%d,%d", x, y)
+	}
+	return fmt.Sprintf("%s\n\n%s\ncursor: %s\n", h.Text(), h.Styles(), cursor)
+}
+
+// TB is the subset of testing.TB used by Golden().
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// Golden compares the given snapshot with the golden file
+// "testdata/<name>.golden" and reports an error if they differ. If the
+// TVIEWTEST_UPDATE environment variable is set, the golden file is written
+// instead.
+func Golden(t TB, name, snapshot string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("tviewtest: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(snapshot), 0644); err != nil {
+			t.Fatalf("tviewtest: %v", err)
+		}
+		return
+	}
+
+	golden, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("tviewtest: %v (set %s=1 to create it)", err, UpdateEnv)
+		return
+	}
+	if string(golden) != snapshot {
+		t.Errorf("tviewtest: snapshot differs from %s:\n%s", path, diff(string(golden), snapshot))
+	}
+}
+
+// diff returns the lines which differ between the expected and the actual
+// text.
+func diff(expected, actual string) string {
+	expectedLines := strings.Split(expected, "\n")
+	actualLines := strings.Split(actual, "\n")
+
+	var buffer strings.Builder
+	for i := 0; i < len(expectedLines) || i < len(actualLines); i++ {
+		var e, a string
+		if i < len(expectedLines) {
+			e = expectedLines[i]
+		}
+		if i < len(actualLines) {
+			a = actualLines[i]
+		}
+		if e != a {
+			fmt.Fprintf(&buffer, "line %d:\n- %s\n+ %s\n", i+1, e, a)
+		}
+	}
+	return buffer.String()
+}
+
+// colorNames maps colors to their names. Where a color has several names, the
+// first in alphabetical order is used.
+var colorNames = func() map[tcell.Color]string {
+	names := make([]string, 0, len(tcell.ColorNames))
+	for name := range tcell.ColorNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	colors := make(map[tcell.Color]string)
+	for _, name := range names {
+		color := tcell.ColorNames[name]
+		if _, ok := colors[color]; !ok {
+			colors[color] = name
+		}
+	}
+	return colors
+}()
+
+// describeStyle returns a human-readable description of a style.
+func describeStyle(style tcell.Style) string {
+	fg, bg, attr := style.Decompose()
+	description := fmt.Sprintf("fg=%s bg=%s", describeColor(fg), describeColor(bg))
+
+	attributes := []struct {
+		mask tcell.AttrMask
+		name string
+	}{
+		{tcell.AttrBold, "bold"},
+		{tcell.AttrBlink, "blink"},
+		{tcell.AttrReverse, "reverse"},
+		{tcell.AttrUnderline, "underline"},
+		{tcell.AttrDim, "dim"},
+		{tcell.AttrItalic, "italic"},
+		{tcell.AttrStrikethrough, "strikethrough"},
+	}
+	for _, attribute := range attributes {
+		if attr&attribute.mask != 0 {
+			description += " " + attribute.name
+		}
+	}
+
+	return description
+}
+
+// describeColor returns the name of a color or its hexadecimal value.
+func describeColor(color tcell.Color) string {
+	if color == tcell.ColorDefault {
+		return "default"
+	}
+	if name, ok := colorNames[color]; ok {
+		return name
+	}
+	if hex := color.Hex(); hex >= 0 {
+		return fmt.Sprintf("#%06x", hex)
+	}
+	return fmt.Sprintf("color%d", color)
+}
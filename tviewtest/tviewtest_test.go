@@ -0,0 +1,182 @@
+package tviewtest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/tcell"
+	"github.com/diamondburned/tview/v2"
+)
+
+// recorder is a TB which records the reported errors.
+type recorder struct {
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	r.fatal = true
+}
+
+func TestType(t *testing.T) {
+	var changed []string
+	input := tview.NewInputField().
+		SetLabel("Name: ").
+		SetFieldWidth(10).
+		SetChangedFunc(func(text string) {
+			changed = append(changed, text)
+		})
+	h := New(input, 20, 1)
+	defer h.Close()
+
+	h.Type("Bob")
+	if text := h.Text(); text != "Name: Bob" {
+		t.Errorf("unexpected screen text %q", text)
+	}
+	if strings.Join(changed, ",") != "B,Bo,Bob" {
+		t.Errorf("unexpected changes %q", changed)
+	}
+
+	h.Key(tcell.KeyBackspace2, 0, tcell.ModNone)
+	if input.GetText() != "Bo" {
+		t.Errorf("unexpected text %q after backspace", input.GetText())
+	}
+}
+
+func TestClick(t *testing.T) {
+	var pressed int
+	button := tview.NewButton("OK").SetSelectedFunc(func() {
+		pressed++
+	})
+	h := New(button, 10, 1)
+	defer h.Close()
+
+	h.Click(4, 0, tcell.Button1)
+	if pressed != 1 {
+		t.Errorf("button was pressed %d times, expected once", pressed)
+	}
+}
+
+func TestPaste(t *testing.T) {
+	input := tview.NewInputField()
+	h := New(input, 20, 1)
+	defer h.Close()
+
+	h.Paste("one\ntwo")
+	if input.GetText() != "one two" {
+		t.Errorf("unexpected text %q after paste", input.GetText())
+	}
+}
+
+func TestResize(t *testing.T) {
+	text := tview.NewTextView().SetText("one two three")
+	h := New(text, 20, 2)
+	defer h.Close()
+
+	if got := h.Text(); got != "one two three\n" {
+		t.Errorf("unexpected screen text %q", got)
+	}
+
+	h.Resize(8, 2)
+	if got := h.Text(); got != "one two\nthree" {
+		t.Errorf("unexpected screen text %q after resize", got)
+	}
+}
+
+func TestSync(t *testing.T) {
+	table := tview.NewTable()
+	h := New(table, 10, 3)
+	defer h.Close()
+
+	for row := 0; row < 3; row++ {
+		row := row
+		h.App.QueueUpdateDraw(func() {
+			table.SetCellSimple(row, 0, fmt.Sprint("row", row))
+		})
+	}
+	h.Sync()
+
+	if got := h.Text(); got != "row0\nrow1\nrow2" {
+		t.Errorf("unexpected screen text %q", got)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	input := tview.NewInputField().SetLabel("Name: ").SetFieldWidth(8)
+	h := New(input, 16, 2)
+	defer h.Close()
+
+	h.Type("Al")
+	Golden(t, "input", h.Snapshot())
+}
+
+func TestGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tviewtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	defer os.Unsetenv(UpdateEnv)
+
+	input := tview.NewInputField().SetText("golden")
+	h := New(input, 10, 1)
+	defer h.Close()
+	snapshot := h.Snapshot()
+
+	// A missing golden file is an error.
+	var r recorder
+	Golden(&r, "text", snapshot)
+	if !r.fatal {
+		t.Error("missing golden file was not reported")
+	}
+
+	// Write the golden file.
+	os.Setenv(UpdateEnv, "1")
+	r = recorder{}
+	Golden(&r, "text", snapshot)
+	if len(r.errors) > 0 {
+		t.Fatalf("writing golden file failed: %v", r.errors)
+	}
+	written, err := ioutil.ReadFile(filepath.Join("testdata", "text.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(written) != snapshot {
+		t.Errorf("golden file contains %q, expected %q", written, snapshot)
+	}
+	os.Unsetenv(UpdateEnv)
+
+	// The same screen matches.
+	r = recorder{}
+	Golden(&r, "text", h.Snapshot())
+	if len(r.errors) > 0 {
+		t.Errorf("unchanged snapshot differs: %v", r.errors)
+	}
+
+	// A changed screen doesn't.
+	h.Type("!")
+	r = recorder{}
+	Golden(&r, "text", h.Snapshot())
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "- golden\n+ golden!") {
+		t.Errorf("changed snapshot was not reported: %v", r.errors)
+	}
+}
@@ -21,6 +21,7 @@ const (
 // Common regular expressions.
 var (
 	colorPattern     = regexp.MustCompile(`\[([a-zA-Z]+|#[0-9a-zA-Z]{6}|\-)?(:([a-zA-Z]+|#[0-9a-zA-Z]{6}|\-)?(:([lbdrusi]+|\-)?)?)?\]`)
+	linkPattern      = regexp.MustCompile(`\[([a-zA-Z]+|#[0-9a-zA-Z]{6}|\-)?(:([a-zA-Z]+|#[0-9a-zA-Z]{6}|\-)?(:([lbdrusi]+|\-)?(:([^\[\]]*))?)?)?\]`)
 	regionPattern    = regexp.MustCompile(`\["([a-zA-Z0-9_,;: \-\.]*)"\]`)
 	escapePattern    = regexp.MustCompile(`\[([a-zA-Z0-9_,;: \-\."#]+)\[(\[*)\]`)
 	nonEscapePattern = regexp.MustCompile(`(\[[a-zA-Z0-9_,;: \-\."#]+\[*)\]`)
@@ -33,6 +34,7 @@ const (
 	colorForegroundPos = 1
 	colorBackgroundPos = 3
 	colorFlagPos       = 5
+	colorURLPos        = 7
 )
 
 // Predefined InputField acceptance functions.
@@ -113,6 +115,20 @@ func styleFromTag(fgColor, bgColor, attributes string, tagSubstrings []string) (
 	return fgColor, bgColor, attributes
 }
 
+// urlFromTag takes the given hyperlink target and modifies it based on the
+// substrings (tagSubstrings) extracted by the regular expression for color
+// tags with hyperlinks (linkPattern). The new target is returned where an empty
+// string means "no hyperlink".
+func urlFromTag(url string, tagSubstrings []string) string {
+	if len(tagSubstrings) > colorURLPos && tagSubstrings[colorURLPos-1] != "" {
+		url = tagSubstrings[colorURLPos]
+		if url == "-" {
+			url = ""
+		}
+	}
+	return url
+}
+
 // overlayStyle mixes a background color with a foreground color (fgColor),
 // a (possibly new) background color (bgColor), and style attributes, and
 // returns the resulting style. For a definition of the colors and attributes,
@@ -175,6 +191,19 @@ func overlayStyle(background tcell.Color, defaultStyle tcell.Style, fgColor, bgC
 // region tags are requested), the string stripped by any tags and escaped, and
 // the screen width of the stripped string.
 func decomposeString(text string, findColors, findRegions bool) (colorIndices [][]int, colors [][]string, regionIndices [][]int, regions [][]string, escapeIndices [][]int, stripped string, width int) {
+	var pattern *regexp.Regexp
+	if findColors {
+		pattern = colorPattern
+	}
+	return decomposeTags(text, pattern, findRegions)
+}
+
+// decomposeTags is like decomposeString() but finds color tags with the given
+// regular expression (colorPattern or linkPattern) or, if it is nil, no color
+// tags.
+func decomposeTags(text string, pattern *regexp.Regexp, findRegions bool) (colorIndices [][]int, colors [][]string, regionIndices [][]int, regions [][]string, escapeIndices [][]int, stripped string, width int) {
+	findColors := pattern != nil
+
 	// Shortcut for the trivial case.
 	if !findColors && !findRegions {
 		return nil, nil, nil, nil, nil, text, runewidth.StringWidth(text)
@@ -182,8 +211,8 @@ func decomposeString(text string, findColors, findRegions bool) (colorIndices []
 
 	// Get positions of any tags.
 	if findColors {
-		colorIndices = colorPattern.FindAllStringIndex(text, -1)
-		colors = colorPattern.FindAllStringSubmatch(text, -1)
+		colorIndices = pattern.FindAllStringIndex(text, -1)
+		colors = pattern.FindAllStringSubmatch(text, -1)
 	}
 	if findRegions {
 		regionIndices = regionPattern.FindAllStringIndex(text, -1)